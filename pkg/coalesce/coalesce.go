@@ -0,0 +1,185 @@
+// Package coalesce — склейка одинаковых запросов в полёте (singleflight) перед любым
+// pool.Client: пока первый GET к пути не вернулся, такие же запросы ждут его ответа,
+// а не идут к апстриму. Полезно при наплыве промахов кэша.
+package coalesce
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"httpclientpool/pkg/pool"
+)
+
+var _ pool.Client = (*Client)(nil)
+
+// KeyFunc — ключ склейки запроса: запросы с одинаковым ключом делят один вызов апстрима.
+// ok = false — запрос идёт сам по себе.
+type KeyFunc func(req pool.Request) (key string, ok bool)
+
+// Options — настройки склейки.
+type Options struct {
+	// Key — ключ склейки. По умолчанию HeaderKey(): метод, путь и все заголовки запроса.
+	Key KeyFunc
+}
+
+// HeaderKey склеивает GET и HEAD без тела с одинаковыми методом, путём и значениями
+// заголовков names (без names — всех заголовков запроса). Остальные методы не склеиваются:
+// ответ на них нельзя отдать другому вызывающему.
+func HeaderKey(names ...string) KeyFunc {
+	canon := make([]string, len(names))
+	for i, n := range names {
+		canon[i] = http.CanonicalHeaderKey(n)
+	}
+	return func(req pool.Request) (string, bool) {
+		method := req.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		if (method != http.MethodGet && method != http.MethodHead) || req.Body != nil {
+			return "", false
+		}
+		var b strings.Builder
+		b.WriteString(method)
+		b.WriteByte(' ')
+		b.WriteString(req.Path)
+		keys := canon
+		if len(names) == 0 {
+			keys = make([]string, 0, len(req.Header))
+			for k := range req.Header {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+		}
+		for _, k := range keys {
+			b.WriteByte('\n')
+			b.WriteString(k)
+			b.WriteByte(':')
+			b.WriteString(strings.Join(req.Header.Values(k), ", "))
+		}
+		return b.String(), true
+	}
+}
+
+// Client — pool.Client со склейкой Get и Do. Каждый вызывающий получает свою копию
+// ответа. Общий вызов отменяется, только когда ctx отменили все, кто его ждёт.
+// Post и Stream идут мимо.
+type Client struct {
+	next pool.Client
+	key  KeyFunc
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done   chan struct{}
+	refs   int
+	cancel context.CancelFunc
+
+	status int
+	body   []byte
+	header http.Header
+	err    error
+}
+
+// New ставит склейку перед next. Close закрывает и next.
+func New(next pool.Client, opts Options) *Client {
+	if opts.Key == nil {
+		opts.Key = HeaderKey()
+	}
+	return &Client{next: next, key: opts.Key, calls: map[string]*call{}}
+}
+
+func (c *Client) Get(ctx context.Context, path string) (pool.Response, error) {
+	return c.do(ctx, pool.Request{Path: path}, func(ctx context.Context) (pool.Response, error) {
+		return c.next.Get(ctx, path)
+	})
+}
+
+func (c *Client) Post(ctx context.Context, path string, body any) (pool.Response, error) {
+	return c.next.Post(ctx, path, body)
+}
+
+func (c *Client) Do(ctx context.Context, req pool.Request) (pool.Response, error) {
+	return c.do(ctx, req, func(ctx context.Context) (pool.Response, error) {
+		return c.next.Do(ctx, req)
+	})
+}
+
+func (c *Client) Stream(ctx context.Context, req pool.Request) (*pool.StreamResponse, error) {
+	return c.next.Stream(ctx, req)
+}
+
+func (c *Client) Close() { c.next.Close() }
+
+func (c *Client) do(ctx context.Context, req pool.Request, send func(context.Context) (pool.Response, error)) (pool.Response, error) {
+	k, ok := c.key(req)
+	if !ok {
+		return send(ctx)
+	}
+
+	c.mu.Lock()
+	cl := c.calls[k]
+	if cl == nil {
+		// Общий вызов не привязан к ctx первого вызывающего: его отмена не должна
+		// обрывать запрос остальным.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[k] = cl
+		go c.run(callCtx, k, cl, send)
+	}
+	cl.refs++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		if cl.err != nil {
+			return nil, cl.err
+		}
+		return &resp{status: cl.status, body: append([]byte(nil), cl.body...), header: cl.header.Clone()}, nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		if cl.refs--; cl.refs == 0 {
+			cl.cancel()
+			if c.calls[k] == cl {
+				delete(c.calls, k)
+			}
+		}
+		c.mu.Unlock()
+		return nil, pool.NewError(ctx, -1, ctx.Err())
+	}
+}
+
+// run выполняет общий вызов и сохраняет копию ответа; ответ апстрима сразу освобождается.
+func (c *Client) run(ctx context.Context, k string, cl *call, send func(context.Context) (pool.Response, error)) {
+	r, err := send(ctx)
+	if err == nil {
+		cl.status, cl.header = r.StatusCode(), r.Header().Clone()
+		cl.body = append([]byte(nil), r.Body()...)
+		r.Release()
+	}
+	cl.err = err
+	cl.cancel()
+
+	// Пришедшие после ответа начинают новый вызов.
+	c.mu.Lock()
+	if c.calls[k] == cl {
+		delete(c.calls, k)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+type resp struct {
+	status int
+	body   []byte
+	header http.Header
+}
+
+func (r *resp) StatusCode() int     { return r.status }
+func (r *resp) Body() []byte        { return r.body }
+func (r *resp) Header() http.Header { return r.header }
+func (r *resp) Release()            {}
@@ -0,0 +1,153 @@
+// Package compress — кодировки gzip, br и zstd по cfg.Compression, общие для обоих бэкендов:
+// распаковка ответов с пределом размера и сжатие тел запросов.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+// AcceptEncoding — значение заголовка Accept-Encoding или "", если сжатие не согласуется.
+func AcceptEncoding(c config.Compression) string {
+	return strings.Join(c.Accept, ", ")
+}
+
+// Supported сообщает, умеет ли пакет распаковывать кодировку enc (значение Content-Encoding).
+func Supported(enc string) bool {
+	return enc == config.EncodingGzip || enc == config.EncodingBrotli || enc == config.EncodingZstd
+}
+
+// CompressRequest сообщает, сжимать ли тело запроса длины n (0 и меньше — длина неизвестна).
+func CompressRequest(c config.Compression, n int64) bool {
+	return c.Request != "" && (n <= 0 || n >= c.RequestMinSize)
+}
+
+// NewReader распаковывает r. Чтение больше limit байт распакованных данных возвращает
+// *pool.BodyTooLargeError. Close освобождает декодер, r не закрывается.
+// Для пустого r (ответ на HEAD, 204) gzip возвращает io.EOF.
+func NewReader(enc string, r io.Reader, limit int64) (io.ReadCloser, error) {
+	var dec io.ReadCloser
+	switch enc {
+	case config.EncodingGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		dec = gr
+	case config.EncodingBrotli:
+		dec = io.NopCloser(brotli.NewReader(r))
+	case config.EncodingZstd:
+		// Один поток: без фоновых горутин декодера на каждый ответ.
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		dec = zr.IOReadCloser()
+	default:
+		return nil, fmt.Errorf("compress: unsupported encoding %q", enc)
+	}
+	return &limitReader{rc: dec, left: limit, limit: limit}, nil
+}
+
+// limitReader отдаёт не больше limit байт; следующий байт сверх предела — ошибка.
+type limitReader struct {
+	rc    io.ReadCloser
+	left  int64
+	limit int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, &pool.BodyTooLargeError{Limit: l.limit}
+	}
+	// Читаем на байт больше остатка, чтобы отличить тело ровно в limit от бомбы.
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.rc.Read(p)
+	if int64(n) > l.left {
+		n = int(l.left)
+		l.left = -1
+		return n, &pool.BodyTooLargeError{Limit: l.limit}
+	}
+	l.left -= int64(n)
+	return n, err
+}
+
+func (l *limitReader) Close() error { return l.rc.Close() }
+
+// Decode распаковывает src целиком и дописывает результат в dst.
+func Decode(dst []byte, enc string, src []byte, limit int64) ([]byte, error) {
+	r, err := NewReader(enc, bytes.NewReader(src), limit)
+	if err == io.EOF {
+		return dst, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	buf := bytes.NewBuffer(dst)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewWriter сжимает записанное в w; Close дописывает хвост потока, w не закрывается.
+func NewWriter(enc string, w io.Writer) (io.WriteCloser, error) {
+	switch enc {
+	case config.EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case config.EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	case config.EncodingZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	default:
+		return nil, fmt.Errorf("compress: unsupported encoding %q", enc)
+	}
+}
+
+// Encode сжимает src целиком и дописывает результат в dst.
+func Encode(dst []byte, enc string, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w, err := NewWriter(enc, buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Pipe сжимает r потоком: из результата читаются сжатые данные. r читается в отдельной
+// горутине и закрывается (если это io.Closer), когда дочитан или результат закрыт.
+func Pipe(enc string, r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := NewWriter(enc, pw)
+		if err == nil {
+			_, err = io.Copy(w, r)
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if c, ok := r.(io.Closer); ok {
+			_ = c.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
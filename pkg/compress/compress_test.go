@@ -0,0 +1,98 @@
+package compress_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"httpclientpool/pkg/compress"
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+var encodings = []string{config.EncodingGzip, config.EncodingBrotli, config.EncodingZstd}
+
+func TestRoundTrip(t *testing.T) {
+	src := []byte(strings.Repeat("hello, compression ", 1000))
+	for _, enc := range encodings {
+		b, err := compress.Encode(nil, enc, src)
+		if err != nil || len(b) >= len(src) {
+			t.Fatalf("%s: encode: %v, %d bytes", enc, err, len(b))
+		}
+		got, err := compress.Decode(nil, enc, b, int64(len(src)))
+		if err != nil || !bytes.Equal(got, src) {
+			t.Fatalf("%s: decode: %v, %d bytes", enc, err, len(got))
+		}
+
+		r, err := compress.NewReader(enc, compress.Pipe(enc, bytes.NewReader(src)), 1<<20)
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		got, err = io.ReadAll(r)
+		_ = r.Close()
+		if err != nil || !bytes.Equal(got, src) {
+			t.Fatalf("%s: pipe: %v, %d bytes", enc, err, len(got))
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	src := make([]byte, 1<<20)
+	for _, enc := range encodings {
+		b, err := compress.Encode(nil, enc, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := compress.Decode(nil, enc, b, int64(len(src))); err != nil {
+			t.Fatalf("%s: body of exactly limit must pass: %v", enc, err)
+		}
+		_, err = compress.Decode(nil, enc, b, int64(len(src))-1)
+		var tl *pool.BodyTooLargeError
+		if !errors.As(err, &tl) || tl.Limit != int64(len(src))-1 {
+			t.Fatalf("%s: want BodyTooLargeError, got %v", enc, err)
+		}
+	}
+}
+
+func TestEmptyAndUnknown(t *testing.T) {
+	if b, err := compress.Decode(nil, config.EncodingGzip, nil, 10); err != nil || len(b) != 0 {
+		t.Fatalf("empty gzip body: %v %q", err, b)
+	}
+	if _, err := compress.NewReader("deflate", strings.NewReader(""), 10); err == nil {
+		t.Fatal("want error for unsupported encoding")
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error { c.closed = true; return nil }
+
+func TestPipeClosesSource(t *testing.T) {
+	src := &closeTracker{Reader: strings.NewReader("data")}
+	if _, err := io.ReadAll(compress.Pipe(config.EncodingGzip, src)); err != nil {
+		t.Fatal(err)
+	}
+	if !src.closed {
+		t.Fatal("source must be closed after the compressed stream is read")
+	}
+}
+
+func TestCompressRequest(t *testing.T) {
+	cm := config.Compression{Request: config.EncodingGzip, RequestMinSize: 100}
+	for _, tc := range []struct {
+		n    int64
+		want bool
+	}{{0, true}, {-1, true}, {99, false}, {100, true}} {
+		if got := compress.CompressRequest(cm, tc.n); got != tc.want {
+			t.Fatalf("n=%d: got %v", tc.n, got)
+		}
+	}
+	if compress.CompressRequest(config.Compression{}, 1<<20) {
+		t.Fatal("compression is off without Request")
+	}
+}
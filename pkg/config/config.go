@@ -1,21 +1,314 @@
 package config
 
-import "time"
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
-	BaseURL               string
-	Size                  int
-	RequestTimeout        time.Duration
-	DialTimeout           time.Duration
-	TlsTimeout            time.Duration
-	IdleConnTimeout       time.Duration
-	MaxConnsPerHost       int
-	InsecureSkipVerify    bool
-	ResponseHeaderTimeout time.Duration
+	Name                  string        `json:"name" yaml:"name"`
+	BaseURL               string        `json:"base_url" yaml:"base_url"`
+	Size                  int           `json:"size" yaml:"size"`
+	RequestTimeout        time.Duration `json:"request_timeout" yaml:"request_timeout"`
+	DialTimeout           time.Duration `json:"dial_timeout" yaml:"dial_timeout"`
+	TlsTimeout            time.Duration `json:"tls_timeout" yaml:"tls_timeout"`
+	IdleConnTimeout       time.Duration `json:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+	MaxConnsPerHost       int           `json:"max_conns_per_host" yaml:"max_conns_per_host"`
+	InsecureSkipVerify    bool          `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	ResponseHeaderTimeout time.Duration `json:"response_header_timeout" yaml:"response_header_timeout"`
+	TLS                   TLS           `json:"tls" yaml:"tls"`
+	Proxy                 Proxy         `json:"proxy" yaml:"proxy"`
+	Compression           Compression   `json:"compression" yaml:"compression"`
+
+	// Protocol — http1 (по умолчанию), http2 (h2 через ALPN, с откатом на HTTP/1.1) или h2c
+	// (HTTP/2 без TLS). HTTP/2 поддерживает только restypool.
+	Protocol string `json:"protocol" yaml:"protocol"`
+	// MaxConcurrentStreams — сколько запросов один член пула одновременно мультиплексирует
+	// в своё h2-соединение. 0 — DefaultMaxConcurrentStreams.
+	MaxConcurrentStreams int `json:"max_concurrent_streams" yaml:"max_concurrent_streams"`
+
+	// Endpoints — несколько базовых URL с весами; члены пула распределяются между ними
+	// пропорционально весам. Взаимоисключающе с BaseURL.
+	Endpoints []Endpoint `json:"endpoints" yaml:"endpoints"`
+	// FailoverThreshold — после стольких подряд транспортных ошибок эндпоинт считается
+	// недоступным и трафик уходит на остальные. 0 — отключено.
+	FailoverThreshold int `json:"failover_threshold" yaml:"failover_threshold"`
+	// FailoverCooldown — через сколько недоступный эндпоинт снова получает трафик.
+	FailoverCooldown time.Duration `json:"failover_cooldown" yaml:"failover_cooldown"`
+
+	// DNSRefresh > 0 включает DNS-режим: хост каждого эндпоинта периодически резолвится,
+	// и члены пула распределяются по полученным адресам (dial на IP, SNI и Host — из URL).
+	DNSRefresh time.Duration `json:"dns_refresh" yaml:"dns_refresh"`
+	// Resolver для DNS-режима. По умолчанию net.DefaultResolver.
+	Resolver Resolver `json:"-" yaml:"-"`
+	// Dialer — свой способ установить соединение (туннель, сокет сайдкара, тестовая сеть).
+	// По умолчанию net.Dialer с DialTimeout.
+	Dialer Dialer `json:"-" yaml:"-"`
+
+	// PooledBuffers — тело ответа Get/Post/Do лежит в буфере из пула, а не в новом срезе:
+	// вызывающий возвращает его через Response.Release и после этого не трогает Body и Header.
+	PooledBuffers bool `json:"pooled_buffers" yaml:"pooled_buffers"`
+
+	// MaxResponseBodySize — предел тела ответа Get/Post/Do в байтах, как оно пришло по сети
+	// (до распаковки): больше — *pool.BodyTooLargeError. 0 — без предела. Stream не ограничивается.
+	MaxResponseBodySize int64 `json:"max_response_body_size" yaml:"max_response_body_size"`
+	// BodyReadTimeout — сколько можно читать тело ответа Get/Post/Do после заголовков,
+	// отдельно от RequestTimeout: медленно капающий ответ обрывается с pool.ErrBodyReadTimeout.
+	// 0 — только RequestTimeout. Stream не ограничивается.
+	BodyReadTimeout time.Duration `json:"body_read_timeout" yaml:"body_read_timeout"`
+	// Idempotency — заголовок Idempotency-Key для POST и PATCH, чтобы их можно было безопасно повторять.
+	Idempotency Idempotency `json:"idempotency" yaml:"idempotency"`
+
+	// Autoscale — автоматическое изменение Size по загрузке пула.
+	Autoscale Autoscale `json:"autoscale" yaml:"autoscale"`
+
+	// Warmup — прогрев соединений при создании пула.
+	Warmup Warmup `json:"warmup" yaml:"warmup"`
+
+	// Discovery — внешний источник списка эндпоинтов (Consul, Kubernetes, файл...).
+	// Пул сверяет членов с каждым обновлением; Endpoints/BaseURL используются, пока первое не пришло.
+	Discovery Discovery `json:"-" yaml:"-"`
+
+	Logger *slog.Logger `json:"-" yaml:"-"`
+}
+
+// Discovery отдаёт поток наборов эндпоинтов. Каждое значение в канале — полный текущий набор.
+// Канал закрывается, когда ctx отменён.
+type Discovery interface {
+	Watch(ctx context.Context) (<-chan []Endpoint, error)
+}
+
+type Dialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// UnixHTTPBase — базовый URL запросов к эндпоинту unix://: путь из Get/Post
+// дописывается к нему, Host — localhost.
+const UnixHTTPBase = "http://localhost"
+
+// UnixSocket возвращает путь к сокету для base URL вида unix:///run/envoy.sock.
+func UnixSocket(baseURL string) (path string, ok bool) {
+	rest, ok := strings.CutPrefix(baseURL, "unix://")
+	if !ok || rest == "" {
+		return "", false
+	}
+	return rest, true
+}
+
+// RedactURL заменяет пароль в userinfo URL на xxxxx. Непарсящийся URL с userinfo
+// скрывается целиком.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		if strings.Contains(raw, "@") {
+			return "xxxxx"
+		}
+		return raw
+	}
+	if u.User == nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// Redacted — копия конфига для показа (debug-хендлер): пароли в BaseURL, Endpoints и
+// Proxy.URL заменены на xxxxx.
+func (c Config) Redacted() Config {
+	c.BaseURL = RedactURL(c.BaseURL)
+	c.Proxy.URL = RedactURL(c.Proxy.URL)
+	if len(c.Endpoints) > 0 {
+		eps := make([]Endpoint, len(c.Endpoints))
+		for i, e := range c.Endpoints {
+			e.URL = RedactURL(e.URL)
+			eps[i] = e
+		}
+		c.Endpoints = eps
+	}
+	return c
+}
+
+// HostPort — host:port из URL с портом по умолчанию, в том виде, в каком его передают в Dial
+// net/http и fasthttp.
+func HostPort(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type Endpoint struct {
+	URL string `json:"url" yaml:"url"`
+	// Weight <= 0 считается равным 1.
+	Weight int `json:"weight" yaml:"weight"`
 }
 
+// UnmarshalText разбирает "https://a.example.com" или "https://a.example.com=3" (URL=вес).
+// Используется для переменных окружения и для краткой записи в YAML. "=" в query или
+// фрагменте — часть URL: у такого URL вес задаётся только полной записью {url, weight}.
+func (e *Endpoint) UnmarshalText(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if i := strings.LastIndex(s, "="); i > 0 && !strings.ContainsAny(s[:i], "?#") {
+		if w, err := strconv.Atoi(s[i+1:]); err == nil {
+			*e = Endpoint{URL: s[:i], Weight: w}
+			return nil
+		}
+	}
+	*e = Endpoint{URL: s, Weight: 1}
+	return nil
+}
+
+// EndpointList возвращает Endpoints или, если они не заданы, единственный эндпоинт BaseURL.
+func (c Config) EndpointList() []Endpoint {
+	if len(c.Endpoints) == 0 {
+		return []Endpoint{{URL: c.BaseURL, Weight: 1}}
+	}
+	out := make([]Endpoint, len(c.Endpoints))
+	for i, e := range c.Endpoints {
+		if e.Weight <= 0 {
+			e.Weight = 1
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// TLS — настройки TLS, общие для обоих бэкендов. Пустые поля — поведение crypto/tls по умолчанию.
+type TLS struct {
+	RootCAFile   string   `json:"root_ca_file" yaml:"root_ca_file"`
+	RootCAPEM    string   `json:"root_ca_pem" yaml:"root_ca_pem"`
+	CertFile     string   `json:"cert_file" yaml:"cert_file"`
+	KeyFile      string   `json:"key_file" yaml:"key_file"`
+	ServerName   string   `json:"server_name" yaml:"server_name"`
+	MinVersion   string   `json:"min_version" yaml:"min_version"`
+	CipherSuites []string `json:"cipher_suites" yaml:"cipher_suites"`
+
+	// Pins — допустимые SHA-256 хэши SubjectPublicKeyInfo в base64 ("sha256/..." или без префикса).
+	// Соединение принимается, если хотя бы один сертификат цепочки совпал с пином.
+	Pins          []string `json:"pins" yaml:"pins"`
+	PinReportOnly bool     `json:"pin_report_only" yaml:"pin_report_only"`
+}
+
+// Autoscale — настройки автомасштабирования. Пул растёт, когда все соединения заняты
+// или среднее ожидание свободного соединения превышает MaxWait, и сжимается до пиковой
+// занятости, если она долго была ниже текущего размера. Ручной Resize не ограничен Min/Max.
+type Autoscale struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	Min     int  `json:"min" yaml:"min"`
+	Max     int  `json:"max" yaml:"max"`
+	// Interval — период, с которым снимается загрузка.
+	Interval time.Duration `json:"interval" yaml:"interval"`
+	// MaxWait — средняя очередь на соединение за Interval, выше которой пул растёт. 0 — только по занятости.
+	MaxWait time.Duration `json:"max_wait" yaml:"max_wait"`
+	// ScaleUpCooldown и ScaleDownCooldown — минимальный интервал после предыдущего изменения размера.
+	// ScaleDownCooldown заодно — окно, за которое берётся пиковая занятость при сжатии.
+	ScaleUpCooldown   time.Duration `json:"scale_up_cooldown" yaml:"scale_up_cooldown"`
+	ScaleDownCooldown time.Duration `json:"scale_down_cooldown" yaml:"scale_down_cooldown"`
+}
+
+// Warmup — прогрев: каждый член пула заранее устанавливает соединение (TCP + TLS),
+// отправляя HEAD на базовый URL или GET на Path.
+type Warmup struct {
+	// Enabled включает прогрев в New. Метод Warmup(ctx) доступен и без него.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Path — путь для прогрева (GET, ответ 4xx/5xx — ошибка). Пусто — HEAD на базовый URL, годится любой статус.
+	Path string `json:"path" yaml:"path"`
+	// MinReady — сколько членов должно прогреться, прежде чем New вернёт пул; остальные
+	// догреваются в фоне. 0 — все. Если столько не набралось за Timeout, New возвращает ошибку.
+	MinReady int           `json:"min_ready" yaml:"min_ready"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`
+}
+
+// Proxy — исходящий прокси для обоих бэкендов.
+type Proxy struct {
+	// URL — http://[user:pass@]host:port (туннель через CONNECT) или socks5://[user:pass@]host:port.
+	URL string `json:"url" yaml:"url"`
+	// NoProxy — хосты, которые ходят напрямую, в формате NO_PROXY: домен (с поддоменами),
+	// .домен (только поддомены), IP, CIDR, host:port или "*".
+	NoProxy []string `json:"no_proxy" yaml:"no_proxy"`
+	// FromEnv — взять прокси из HTTPS_PROXY/HTTP_PROXY/NO_PROXY. Взаимоисключающе с URL и NoProxy.
+	FromEnv bool `json:"from_env" yaml:"from_env"`
+}
+
+// Compression — согласование сжатия ответов и сжатие тел запросов, одинаковое в обоих бэкендах.
+type Compression struct {
+	// Accept — кодировки для Accept-Encoding в порядке предпочтения: gzip, br, zstd. Ответы
+	// в них распаковываются прозрачно. Пусто — поведение бэкенда по умолчанию
+	// (resty просит и распаковывает gzip и deflate без предела, fasthttp сжатие не согласует).
+	Accept []string `json:"accept" yaml:"accept"`
+	// MaxDecompressedSize — предел распакованного тела (защита от zip-бомб): больше —
+	// ошибка *pool.BodyTooLargeError. 0 — DefaultMaxDecompressedSize.
+	MaxDecompressedSize int64 `json:"max_decompressed_size" yaml:"max_decompressed_size"`
+	// Request — кодировка тел запросов (gzip, br, zstd). Пусто — не сжимать.
+	Request string `json:"request" yaml:"request"`
+	// RequestMinSize — тела короче не сжимаются; тела неизвестной длины сжимаются всегда.
+	RequestMinSize int64 `json:"request_min_size" yaml:"request_min_size"`
+}
+
+// DecompressLimit — MaxDecompressedSize или значение по умолчанию.
+func (c Compression) DecompressLimit() int64 {
+	if c.MaxDecompressedSize > 0 {
+		return c.MaxDecompressedSize
+	}
+	return DefaultMaxDecompressedSize
+}
+
+// Idempotency — ключ идемпотентности (draft-ietf-httpapi-idempotency-key-header): один на
+// логический вызов POST/PATCH, сервер по нему узнаёт повтор и не выполняет операцию дважды.
+type Idempotency struct {
+	// Enabled — добавлять ключ к каждому POST и PATCH, если вызывающий не задал заголовок сам.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Header — имя заголовка. Пусто — DefaultIdempotencyHeader.
+	Header string `json:"header" yaml:"header"`
+	// Generator — генератор ключей. nil — случайный UUID v4.
+	Generator func() string `json:"-" yaml:"-"`
+}
+
+// HeaderName — Header или имя по умолчанию.
+func (i Idempotency) HeaderName() string {
+	if i.Header != "" {
+		return i.Header
+	}
+	return DefaultIdempotencyHeader
+}
+
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+
+	DefaultMaxDecompressedSize = 64 << 20
+)
+
+const (
+	ProtocolHTTP1 = "http1"
+	ProtocolHTTP2 = "http2"
+	ProtocolH2C   = "h2c"
+
+	DefaultMaxConcurrentStreams = 100
+)
+
 func DefaultConfig() Config {
 	return Config{
+		Name:                  "",
 		BaseURL:               "",
 		Size:                  8,
 		RequestTimeout:        10 * time.Second,
@@ -23,7 +316,45 @@ func DefaultConfig() Config {
 		TlsTimeout:            2 * time.Second,
 		IdleConnTimeout:       90 * time.Second,
 		MaxConnsPerHost:       1,
-		InsecureSkipVerify:    true,
+		InsecureSkipVerify:    false,
 		ResponseHeaderTimeout: 0,
+		Protocol:              ProtocolHTTP1,
+		MaxConcurrentStreams:  DefaultMaxConcurrentStreams,
+		FailoverThreshold:     3,
+		FailoverCooldown:      10 * time.Second,
+		Autoscale: Autoscale{
+			Min:               1,
+			Max:               64,
+			Interval:          time.Second,
+			MaxWait:           50 * time.Millisecond,
+			ScaleUpCooldown:   5 * time.Second,
+			ScaleDownCooldown: time.Minute,
+		},
+		Warmup: Warmup{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// TestConfig — пресет для тестов и бенчмарков против httptest-серверов с самоподписанным сертификатом.
+// Проверка TLS отключена, поэтому пул при создании пишет предупреждение.
+func TestConfig() Config {
+	cfg := DefaultConfig()
+	cfg.InsecureSkipVerify = true
+	return cfg
+}
+
+// Dial возвращает Dialer или net.Dialer с DialTimeout.
+func (c Config) Dial() Dialer {
+	if c.Dialer != nil {
+		return c.Dialer
+	}
+	return &net.Dialer{Timeout: c.DialTimeout}
+}
+
+func (c Config) Log() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
 	}
+	return slog.Default()
 }
@@ -0,0 +1,216 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("HTTPPOOL_SIZE", "16")
+	t.Setenv("HTTPPOOL_REQUEST_TIMEOUT", "3s")
+	t.Setenv("HTTPPOOL_BASE_URL", "https://api.example.com")
+	t.Setenv("HTTPPOOL_INSECURE_SKIP_VERIFY", "false")
+
+	cfg, err := config.FromEnv("HTTPPOOL")
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if cfg.Size != 16 || cfg.RequestTimeout != 3*time.Second || cfg.BaseURL != "https://api.example.com" || cfg.InsecureSkipVerify {
+		t.Fatalf("unexpected cfg: %+v", cfg)
+	}
+	if cfg.DialTimeout != config.DefaultConfig().DialTimeout {
+		t.Fatalf("unset var must keep default, got %s", cfg.DialTimeout)
+	}
+}
+
+func TestFromEnv_BadValue(t *testing.T) {
+	t.Setenv("HTTPPOOL_DIAL_TIMEOUT", "soon")
+
+	_, err := config.FromEnv("HTTPPOOL_")
+	if err == nil || !strings.Contains(err.Error(), "HTTPPOOL_DIAL_TIMEOUT") {
+		t.Fatalf("want error naming the variable, got %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yml := filepath.Join(dir, "pool.yaml")
+	if err := os.WriteFile(yml, []byte("base_url: https://a.example.com\nsize: 4\nrequest_timeout: 1500ms\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadFile(yml)
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	if cfg.Size != 4 || cfg.RequestTimeout != 1500*time.Millisecond || cfg.BaseURL != "https://a.example.com" {
+		t.Fatalf("unexpected yaml cfg: %+v", cfg)
+	}
+
+	js := filepath.Join(dir, "pool.json")
+	if err := os.WriteFile(js, []byte(`{"size": 2, "dial_timeout": "250ms"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = config.LoadFile(js)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if cfg.Size != 2 || cfg.DialTimeout != 250*time.Millisecond {
+		t.Fatalf("unexpected json cfg: %+v", cfg)
+	}
+
+	cfg, err = config.ParseYAML([]byte("tls:\n  server_name: api.internal\n  root_ca_pem: |\n    -----BEGIN CERTIFICATE-----\n  cipher_suites: [TLS_AES_128_GCM_SHA256]\n"))
+	if err != nil {
+		t.Fatalf("yaml tls: %v", err)
+	}
+	if cfg.TLS.ServerName != "api.internal" || len(cfg.TLS.RootCAPEM) == 0 || len(cfg.TLS.CipherSuites) != 1 {
+		t.Fatalf("unexpected tls cfg: %+v", cfg.TLS)
+	}
+
+	if _, err := config.ParseYAML([]byte("sise: 4\n")); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := config.DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config must be valid: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Size = 0
+	cfg.MaxConnsPerHost = 0
+	cfg.RequestTimeout = -time.Second
+	cfg.BaseURL = "://nope"
+	cfg.MaxResponseBodySize = -1
+	cfg.BodyReadTimeout = -time.Second
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	for _, want := range []string{"size", "max_conns_per_host", "request_timeout", "base_url", "max_response_body_size", "body_read_timeout"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	t.Setenv("HTTPPOOL_ENDPOINTS", "https://a.example.com=2,https://b.example.com")
+	cfg, err := config.FromEnv("HTTPPOOL")
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	want := []config.Endpoint{{URL: "https://a.example.com", Weight: 2}, {URL: "https://b.example.com", Weight: 1}}
+	if len(cfg.Endpoints) != 2 || cfg.Endpoints[0] != want[0] || cfg.Endpoints[1] != want[1] {
+		t.Fatalf("unexpected endpoints: %+v", cfg.Endpoints)
+	}
+
+	cfg, err = config.ParseYAML([]byte("endpoints:\n  - https://a.example.com\n  - url: https://b.example.com\n    weight: 3\n"))
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	if cfg.Endpoints[0].URL != "https://a.example.com" || cfg.Endpoints[1].Weight != 3 {
+		t.Fatalf("unexpected endpoints: %+v", cfg.Endpoints)
+	}
+
+	// "=" в query — не вес.
+	for raw, want := range map[string]config.Endpoint{
+		"https://a.example.com/?x=1":   {URL: "https://a.example.com/?x=1", Weight: 1},
+		"https://a.example.com/p#k=2":  {URL: "https://a.example.com/p#k=2", Weight: 1},
+		"https://a.example.com/base=4": {URL: "https://a.example.com/base", Weight: 4},
+	} {
+		var e config.Endpoint
+		if err := e.UnmarshalText([]byte(raw)); err != nil || e != want {
+			t.Fatalf("%s: got %+v, %v; want %+v", raw, e, err, want)
+		}
+	}
+
+	cfg.BaseURL = "https://c.example.com"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("want mutually exclusive error, got %v", err)
+	}
+}
+
+func TestAutoscale(t *testing.T) {
+	t.Setenv("HTTPPOOL_AUTOSCALE_ENABLED", "true")
+	t.Setenv("HTTPPOOL_AUTOSCALE_MAX", "32")
+	t.Setenv("HTTPPOOL_AUTOSCALE_SCALE_DOWN_COOLDOWN", "30s")
+	cfg, err := config.FromEnv("HTTPPOOL")
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if a := cfg.Autoscale; !a.Enabled || a.Min != 1 || a.Max != 32 || a.ScaleDownCooldown != 30*time.Second {
+		t.Fatalf("unexpected autoscale: %+v", a)
+	}
+
+	cfg.Autoscale.Min = 4
+	cfg.Autoscale.Max = 2
+	cfg.Autoscale.Interval = 0
+	err = cfg.Validate()
+	for _, want := range []string{"autoscale.max", "autoscale.interval"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %v does not mention %s", err, want)
+		}
+	}
+}
+
+func TestUnixBaseURL(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "unix:///run/envoy.sock"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unix base url must be valid: %v", err)
+	}
+	if path, ok := config.UnixSocket(cfg.BaseURL); !ok || path != "/run/envoy.sock" {
+		t.Fatalf("UnixSocket = %q, %v", path, ok)
+	}
+
+	cfg.BaseURL = "unix://"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "socket path") {
+		t.Fatalf("want socket path error, got %v", err)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	t.Setenv("HTTPPOOL_COMPRESSION_ACCEPT", "zstd,br,gzip")
+	t.Setenv("HTTPPOOL_COMPRESSION_REQUEST", "gzip")
+	cfg, err := config.FromEnv("HTTPPOOL")
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if c := cfg.Compression; len(c.Accept) != 3 || c.Accept[0] != "zstd" || c.Request != "gzip" || c.DecompressLimit() != config.DefaultMaxDecompressedSize {
+		t.Fatalf("unexpected compression: %+v", c)
+	}
+
+	cfg.Compression.Accept = []string{"gzip", "deflate"}
+	cfg.Compression.Request = "lz4"
+	cfg.Compression.MaxDecompressedSize = -1
+	err = cfg.Validate()
+	for _, want := range []string{`"deflate"`, "compression.request", "compression.max_decompressed_size"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %v does not mention %s", err, want)
+		}
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Setenv("HTTPPOOL_IDEMPOTENCY_ENABLED", "true")
+	cfg, err := config.FromEnv("HTTPPOOL")
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if i := cfg.Idempotency; !i.Enabled || i.HeaderName() != config.DefaultIdempotencyHeader {
+		t.Fatalf("unexpected idempotency: %+v", i)
+	}
+
+	cfg.Idempotency.Header = "Idempotency Key"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "idempotency.header") {
+		t.Fatalf("error %v does not mention idempotency.header", err)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FromEnv строит конфиг из DefaultConfig и переменных окружения с префиксом:
+// для префикса "HTTPPOOL" поле Size читается из HTTPPOOL_SIZE, RequestTimeout — из
+// HTTPPOOL_REQUEST_TIMEOUT (длительности в формате Go: "3s", "150ms").
+func FromEnv(prefix string) (Config, error) {
+	cfg := DefaultConfig()
+	if err := ApplyEnv(&cfg, prefix); err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// ApplyEnv перекрывает поля cfg заданными переменными окружения. Незаданные переменные не трогают поле.
+func ApplyEnv(cfg *Config, prefix string) error {
+	prefix = strings.TrimSuffix(strings.ToUpper(prefix), "_")
+	return applyEnv(reflect.ValueOf(cfg).Elem(), prefix)
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := envName(prefix, f)
+		if name == "" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+			if err := applyEnv(fv, name); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setFromString(fv, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func envName(prefix string, f reflect.StructField) string {
+	tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		tag = f.Name
+	}
+	name := strings.ToUpper(tag)
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+	return name
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setFromString(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if raw == "" {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(raw))
+			return nil
+		}
+		parts := strings.Split(raw, ",")
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := setFromString(s.Index(i), p); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// LoadFile читает конфиг из YAML (.yaml/.yml) или JSON (.json) файла поверх DefaultConfig.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	case ".json":
+		return ParseJSON(data)
+	default:
+		return Config{}, fmt.Errorf("config: unsupported file extension %q", ext)
+	}
+}
+
+// ParseYAML разбирает YAML поверх DefaultConfig. Длительности — строки Go ("3s"); неизвестные ключи — ошибка.
+func ParseYAML(data []byte) (Config, error) {
+	cfg := DefaultConfig()
+	if err := decode(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// ParseJSON разбирает JSON поверх DefaultConfig. JSON — подмножество YAML, поэтому
+// используется тот же декодер и те же правила для длительностей и ключей.
+func ParseJSON(data []byte) (Config, error) {
+	return ParseYAML(data)
+}
+
+func decode(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Validate проверяет конфиг и возвращает объединённую (errors.Join) ошибку со всеми найденными проблемами.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Size < 1 {
+		errs = append(errs, fmt.Errorf("size must be >= 1, got %d", c.Size))
+	}
+	if c.MaxConnsPerHost < 1 {
+		errs = append(errs, fmt.Errorf("max_conns_per_host must be >= 1, got %d", c.MaxConnsPerHost))
+	}
+
+	for _, d := range []struct {
+		name string
+		v    time.Duration
+	}{
+		{"request_timeout", c.RequestTimeout},
+		{"dial_timeout", c.DialTimeout},
+		{"tls_timeout", c.TlsTimeout},
+		{"idle_conn_timeout", c.IdleConnTimeout},
+		{"response_header_timeout", c.ResponseHeaderTimeout},
+		{"failover_cooldown", c.FailoverCooldown},
+		{"dns_refresh", c.DNSRefresh},
+		{"autoscale.max_wait", c.Autoscale.MaxWait},
+		{"autoscale.scale_up_cooldown", c.Autoscale.ScaleUpCooldown},
+		{"autoscale.scale_down_cooldown", c.Autoscale.ScaleDownCooldown},
+		{"warmup.timeout", c.Warmup.Timeout},
+		{"body_read_timeout", c.BodyReadTimeout},
+	} {
+		if d.v < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", d.name, d.v))
+		}
+	}
+
+	if c.BaseURL != "" {
+		if err := validateURL(c.BaseURL); err != nil {
+			errs = append(errs, fmt.Errorf("base_url: %w", err))
+		}
+	}
+
+	if c.BaseURL != "" && len(c.Endpoints) > 0 {
+		errs = append(errs, errors.New("base_url and endpoints are mutually exclusive"))
+	}
+	for i, e := range c.Endpoints {
+		if err := validateURL(e.URL); err != nil {
+			errs = append(errs, fmt.Errorf("endpoints[%d]: %w", i, err))
+		}
+		if e.Weight < 0 {
+			errs = append(errs, fmt.Errorf("endpoints[%d]: weight must not be negative, got %d", i, e.Weight))
+		}
+	}
+	if c.FailoverThreshold < 0 {
+		errs = append(errs, fmt.Errorf("failover_threshold must not be negative, got %d", c.FailoverThreshold))
+	}
+
+	switch c.Protocol {
+	case "", ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C:
+	default:
+		errs = append(errs, fmt.Errorf("protocol must be one of %s, %s, %s; got %q", ProtocolHTTP1, ProtocolHTTP2, ProtocolH2C, c.Protocol))
+	}
+	if c.MaxConcurrentStreams < 0 {
+		errs = append(errs, fmt.Errorf("max_concurrent_streams must not be negative, got %d", c.MaxConcurrentStreams))
+	}
+
+	if a := c.Autoscale; a.Enabled {
+		if a.Min < 1 {
+			errs = append(errs, fmt.Errorf("autoscale.min must be >= 1, got %d", a.Min))
+		}
+		if a.Max < a.Min {
+			errs = append(errs, fmt.Errorf("autoscale.max must be >= autoscale.min, got %d < %d", a.Max, a.Min))
+		}
+		if a.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("autoscale.interval must be positive, got %s", a.Interval))
+		}
+	}
+
+	if p := c.Proxy; p.URL != "" {
+		if p.FromEnv {
+			errs = append(errs, errors.New("proxy: url and from_env are mutually exclusive"))
+		}
+		if u, err := url.Parse(p.URL); err != nil {
+			errs = append(errs, fmt.Errorf("proxy.url: %w", err))
+		} else if u.Scheme != "http" && u.Scheme != "socks5" && u.Scheme != "socks5h" {
+			errs = append(errs, fmt.Errorf("proxy.url: unsupported scheme %q, want http, socks5 or socks5h", u.Scheme))
+		} else if u.Host == "" {
+			errs = append(errs, fmt.Errorf("proxy.url: missing host in %q", p.URL))
+		}
+	} else if p.FromEnv && len(p.NoProxy) > 0 {
+		errs = append(errs, errors.New("proxy: no_proxy is taken from NO_PROXY when from_env is set"))
+	}
+
+	if c.MaxResponseBodySize < 0 {
+		errs = append(errs, fmt.Errorf("max_response_body_size must not be negative, got %d", c.MaxResponseBodySize))
+	}
+
+	cm := c.Compression
+	for _, enc := range cm.Accept {
+		if !knownEncoding(enc) {
+			errs = append(errs, fmt.Errorf("compression.accept: unsupported encoding %q, want gzip, br or zstd", enc))
+		}
+	}
+	if cm.Request != "" && !knownEncoding(cm.Request) {
+		errs = append(errs, fmt.Errorf("compression.request: unsupported encoding %q, want gzip, br or zstd", cm.Request))
+	}
+	if cm.MaxDecompressedSize < 0 {
+		errs = append(errs, fmt.Errorf("compression.max_decompressed_size must not be negative, got %d", cm.MaxDecompressedSize))
+	}
+	if cm.RequestMinSize < 0 {
+		errs = append(errs, fmt.Errorf("compression.request_min_size must not be negative, got %d", cm.RequestMinSize))
+	}
+
+	if h := c.Idempotency.Header; h != "" && !httpguts.ValidHeaderFieldName(h) {
+		errs = append(errs, fmt.Errorf("idempotency.header: invalid header name %q", h))
+	}
+
+	if c.Warmup.MinReady < 0 {
+		errs = append(errs, fmt.Errorf("warmup.min_ready must not be negative, got %d", c.Warmup.MinReady))
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls: cert_file and key_file must be set together"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(raw string) error {
+	if strings.HasPrefix(raw, "unix:") {
+		if _, ok := UnixSocket(raw); !ok {
+			return fmt.Errorf("missing socket path in %q, want unix:///path/to.sock", raw)
+		}
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", raw)
+	}
+	return nil
+}
+
+func knownEncoding(enc string) bool {
+	return enc == EncodingGzip || enc == EncodingBrotli || enc == EncodingZstd
+}
@@ -0,0 +1,147 @@
+// Package discovery — готовые реализации pool.Discovery: статический список и файл на диске.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"httpclientpool/pkg/config"
+)
+
+// Static отдаёт один и тот же набор эндпоинтов один раз. Полезен в тестах и как заглушка.
+func Static(eps ...config.Endpoint) config.Discovery { return static(eps) }
+
+type static []config.Endpoint
+
+func (s static) Watch(ctx context.Context) (<-chan []config.Endpoint, error) {
+	ch := make(chan []config.Endpoint, 1)
+	ch <- slices.Clone(s)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// DefaultFileInterval — период опроса файла, если interval <= 0.
+const DefaultFileInterval = time.Second
+
+// File читает список эндпоинтов из JSON- или YAML-файла и перечитывает его, когда у файла
+// меняется время модификации или размер. Элемент списка — строка "url" / "url=вес" или
+// объект {url, weight}:
+//
+//	["https://a.example.com=3", {"url": "https://b.example.com", "weight": 1}]
+//
+// Если файл не читается или не разбирается при старте, Watch возвращает ошибку. Ошибки
+// при последующих перечитываниях логируются, а пул остаётся с последним удачным набором.
+func File(path string, interval time.Duration, log *slog.Logger) config.Discovery {
+	if interval <= 0 {
+		interval = DefaultFileInterval
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	return &file{path: path, interval: interval, log: log}
+}
+
+type file struct {
+	path     string
+	interval time.Duration
+	log      *slog.Logger
+}
+
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+func (f *file) Watch(ctx context.Context) (<-chan []config.Endpoint, error) {
+	st, eps, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []config.Endpoint, 1)
+	ch <- eps
+	go f.poll(ctx, ch, st, eps)
+	return ch, nil
+}
+
+func (f *file) poll(ctx context.Context, ch chan<- []config.Endpoint, st fileState, last []config.Endpoint) {
+	defer close(ch)
+	t := time.NewTicker(f.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		fi, err := os.Stat(f.path)
+		if err != nil {
+			f.log.Warn("httpclientpool: discovery file unavailable, keeping last endpoints", "path", f.path, "err", err)
+			continue
+		}
+		if fi.ModTime().Equal(st.mod) && fi.Size() == st.size {
+			continue
+		}
+		next, eps, err := f.read()
+		if err != nil {
+			f.log.Warn("httpclientpool: discovery file is invalid, keeping last endpoints", "path", f.path, "err", err)
+			continue
+		}
+		st = next
+		if slices.Equal(eps, last) {
+			continue
+		}
+		last = eps
+		select {
+		case ch <- eps:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *file) read() (fileState, []config.Endpoint, error) {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return fileState{}, nil, err
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fileState{}, nil, err
+	}
+	eps, err := Parse(data)
+	if err != nil {
+		return fileState{}, nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+	return fileState{mod: fi.ModTime(), size: fi.Size()}, eps, nil
+}
+
+// Parse разбирает список эндпоинтов в JSON или YAML (JSON — подмножество YAML).
+// Пустой список — ошибка: пул не может работать без эндпоинтов.
+func Parse(data []byte) ([]config.Endpoint, error) {
+	var eps []config.Endpoint
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&eps); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if len(eps) == 0 {
+		return nil, fmt.Errorf("discovery: empty endpoint list")
+	}
+	for i, e := range eps {
+		if e.URL == "" {
+			return nil, fmt.Errorf("discovery: endpoint %d: empty url", i)
+		}
+	}
+	return eps, nil
+}
@@ -0,0 +1,103 @@
+package discovery_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/discovery"
+)
+
+func TestParse(t *testing.T) {
+	want := []config.Endpoint{{URL: "https://a", Weight: 3}, {URL: "https://b", Weight: 1}}
+	for name, data := range map[string]string{
+		"yaml": "- https://a=3\n- url: https://b\n  weight: 1\n",
+		"json": `[{"url": "https://a", "weight": 3}, "https://b"]`,
+	} {
+		got, err := discovery.Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "[]", "- url: https://a\n  wieght: 2\n", "- weight: 2\n"} {
+		if _, err := discovery.Parse([]byte(bad)); err == nil {
+			t.Errorf("want error for %q", bad)
+		}
+	}
+}
+
+func TestStatic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := discovery.Static(config.Endpoint{URL: "https://a"}).Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eps := <-ch; len(eps) != 1 || eps[0].URL != "https://a" {
+		t.Fatalf("unexpected endpoints %v", eps)
+	}
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("channel must be closed after ctx is done")
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	write := func(data string, mod time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// Явное mtime, чтобы изменение было видно и на ФС с грубым разрешением времени.
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("- https://a\n", now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch, err := discovery.File(path, 10*time.Millisecond, nil).Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() []config.Endpoint {
+		t.Helper()
+		select {
+		case eps := <-ch:
+			return eps
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for discovery update")
+			return nil
+		}
+	}
+
+	if eps := next(); len(eps) != 1 || eps[0].URL != "https://a" {
+		t.Fatalf("unexpected initial endpoints %v", eps)
+	}
+
+	// Битый файл игнорируется, следующий корректный — применяется.
+	write("- url: [\n", now.Add(time.Second))
+	time.Sleep(50 * time.Millisecond)
+	write("- https://a\n- https://b=2\n", now.Add(2*time.Second))
+	want := []config.Endpoint{{URL: "https://a", Weight: 1}, {URL: "https://b", Weight: 2}}
+	if eps := next(); !reflect.DeepEqual(eps, want) {
+		t.Fatalf("got %v, want %v", eps, want)
+	}
+}
+
+func TestFile_MissingAtStart(t *testing.T) {
+	d := discovery.File(filepath.Join(t.TempDir(), "nope.json"), 0, nil)
+	if _, err := d.Watch(context.Background()); err == nil {
+		t.Fatal("want error for missing file")
+	}
+}
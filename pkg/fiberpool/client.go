@@ -2,18 +2,20 @@ package fiberpool
 
 import (
 	"crypto/tls"
-	"net"
 
 	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/proxyconf"
 
 	fibercli "github.com/gofiber/fiber/v3/client"
 	"github.com/valyala/fasthttp"
 )
 
-func newFiberBase(cfg config.Config) *fasthttp.Client {
+// newFiberBase: если addr задан (DNS-режим), dial идёт на него, а не на хост из URL.
+// SNI и Host при этом остаются от URL.
+func newFiberBase(cfg config.Config, tc *tls.Config, pf proxyconf.Func, addr string) *fasthttp.Client {
 	return &fasthttp.Client{
-		Dial:                func(addr string) (net.Conn, error) { return fasthttp.DialTimeout(addr, cfg.DialTimeout) },
-		TLSConfig:           &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+		Dial:                newDial(cfg, pf, addr),
+		TLSConfig:           tc,
 		ReadTimeout:         cfg.RequestTimeout,
 		WriteTimeout:        cfg.RequestTimeout,
 		MaxIdleConnDuration: cfg.IdleConnTimeout,
@@ -22,6 +24,42 @@ func newFiberBase(cfg config.Config) *fasthttp.Client {
 	}
 }
 
-func newFiberClient(cfg config.Config) *fibercli.Client {
-	return fibercli.NewWithClient(newFiberBase(cfg)).SetTimeout(cfg.RequestTimeout).SetBaseURL(cfg.BaseURL)
+// streamBufferSize — сколько тела fasthttp читает вместе с заголовками; ответы длиннее
+// с известным Content-Length отдаются потоком (fasthttp стримит только то, что не влезло в лимит).
+const streamBufferSize = 64 << 10
+
+// conn — член пула: fiber-клиент и его fasthttp-клиент, через который закрываются соединения.
+// tr — транспорт сжатия и лимитов тела (nil, если они не настроены), см. withTransport.
+// stream — отдельный fasthttp-клиент для Stream со своими соединениями: лимит тела,
+// нужный для стриминга, сломал бы обычные Get/Post. Соединения он открывает по требованию.
+type conn struct {
+	client  *fibercli.Client
+	base    *fasthttp.Client
+	stream  *fasthttp.Client
+	baseURL string
+	tr      *transport
+}
+
+func newConn(cfg config.Config, tc *tls.Config, pf proxyconf.Func, addr string) *conn {
+	base := newFiberBase(cfg, tc, pf, addr)
+	stream := newFiberBase(cfg, tc, pf, addr)
+	stream.StreamResponseBody = true
+	stream.MaxResponseBodySize = streamBufferSize
+	baseURL := cfg.BaseURL
+	if _, ok := config.UnixSocket(baseURL); ok {
+		baseURL = config.UnixHTTPBase
+	}
+	c := &conn{
+		client:  fibercli.NewWithClient(base).SetTimeout(cfg.RequestTimeout).SetBaseURL(baseURL),
+		base:    base,
+		stream:  stream,
+		baseURL: baseURL,
+	}
+	c.withTransport(cfg)
+	return c
+}
+
+func (c *conn) close() {
+	c.base.CloseIdleConnections()
+	c.stream.CloseIdleConnections()
 }
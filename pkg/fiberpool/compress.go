@@ -0,0 +1,81 @@
+package fiberpool
+
+import (
+	"io"
+	"net/http"
+
+	"httpclientpool/pkg/compress"
+
+	"github.com/valyala/fasthttp"
+)
+
+// compressRequest сжимает тело запроса в памяти. При повторе запроса Content-Encoding
+// уже выставлен — тело второй раз не сжимается. Потоковые тела сжимает conn.compressBody.
+func (t *transport) compressRequest(req *fasthttp.Request) error {
+	if t.accept != "" && len(req.Header.Peek(fasthttp.HeaderAcceptEncoding)) == 0 {
+		req.Header.Set(fasthttp.HeaderAcceptEncoding, t.accept)
+	}
+	if req.IsBodyStream() || len(req.Header.ContentEncoding()) != 0 ||
+		len(req.Body()) == 0 || !compress.CompressRequest(t.cm, int64(len(req.Body()))) {
+		return nil
+	}
+	b, err := compress.Encode(nil, t.cm.Request, req.Body())
+	if err != nil {
+		return err
+	}
+	req.SetBodyRaw(b)
+	req.Header.SetContentEncoding(t.cm.Request)
+	return nil
+}
+
+// decodeResponse распаковывает прочитанное тело ответа с пределом. Потоковые ответы
+// распаковывает streamBody.
+func (t *transport) decodeResponse(resp *fasthttp.Response) error {
+	enc := t.decodes(resp)
+	if enc == "" || len(resp.Body()) == 0 {
+		return nil
+	}
+	b, err := compress.Decode(nil, enc, resp.Body(), t.cm.DecompressLimit())
+	if err != nil {
+		return err
+	}
+	// Как net/http для распакованных ответов: Content-Length выставит SetBody.
+	resp.Header.Del(fasthttp.HeaderContentEncoding)
+	resp.SetBody(b)
+	return nil
+}
+
+// decodes возвращает кодировку тела resp, если его нужно распаковать.
+func (t *transport) decodes(resp *fasthttp.Response) string {
+	enc := string(resp.Header.ContentEncoding())
+	if len(t.cm.Accept) == 0 || !compress.Supported(enc) {
+		return ""
+	}
+	return enc
+}
+
+// compressBody сжимает потоковое тело запроса; fasthttp отправит его chunked.
+func (c *conn) compressBody(freq *fasthttp.Request, body io.Reader, size int) (io.Reader, int) {
+	t := c.tr
+	if t == nil || len(freq.Header.ContentEncoding()) != 0 || !compress.CompressRequest(t.cm, int64(size)) {
+		return body, size
+	}
+	freq.Header.SetContentEncoding(t.cm.Request)
+	return compress.Pipe(t.cm.Request, body), -1
+}
+
+// decodeStream готовит распаковку тела потокового ответа: кодировка и предел для streamBody.
+// Заголовки ответа fasthttp не меняются — по Content-Length он дочитывает тело из соединения;
+// Content-Encoding и Content-Length убираются только из h.
+func (c *conn) decodeStream(resp *fasthttp.Response, h http.Header) (string, int64) {
+	t := c.tr
+	if t == nil {
+		return "", 0
+	}
+	enc := t.decodes(resp)
+	if enc != "" {
+		h.Del(fasthttp.HeaderContentEncoding)
+		h.Del(fasthttp.HeaderContentLength)
+	}
+	return enc, t.cm.DecompressLimit()
+}
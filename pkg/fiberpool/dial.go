@@ -0,0 +1,88 @@
+package fiberpool
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/proxyconf"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpproxy"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newDial выбирает для каждого dial прокси по pf (с учётом NoProxy) или идёт напрямую.
+// Напрямую адрес DNS-режима addr подменяет только хост из BaseURL (как в restypool): абсолютные
+// URL и редиректы на другие хосты идут по своему адресу. Через прокси туннель строится
+// к хосту из URL, адреса DNS-режима не используются.
+// Для unix:// все соединения идут в сокет.
+func newDial(cfg config.Config, pf proxyconf.Func, addr string) fasthttp.DialFunc {
+	if socket, ok := config.UnixSocket(cfg.BaseURL); ok {
+		return func(string) (net.Conn, error) { return dialTimeout(cfg, "unix", socket) }
+	}
+	target := config.HostPort(cfg.BaseURL)
+	direct := func(a string) (net.Conn, error) {
+		switch {
+		case addr != "" && a == target:
+			// fasthttp.DialTimeout умеет только tcp4, а резолвер может вернуть IPv6.
+			return dialTimeout(cfg, "tcp", addr)
+		case cfg.Dialer != nil:
+			return dialTimeout(cfg, "tcp", a)
+		}
+		return fasthttp.DialTimeout(a, cfg.DialTimeout)
+	}
+	if pf == nil {
+		return direct
+	}
+
+	scheme := "https"
+	if u, err := url.Parse(cfg.BaseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	var (
+		mu      sync.Mutex
+		viaURLs = map[string]fasthttp.DialFunc{}
+	)
+	return func(a string) (net.Conn, error) {
+		pu, err := pf(&url.URL{Scheme: scheme, Host: a})
+		if err != nil {
+			return nil, err
+		}
+		if pu == nil {
+			return direct(a)
+		}
+
+		key := pu.String()
+		mu.Lock()
+		via, ok := viaURLs[key]
+		if !ok {
+			d := &fasthttpproxy.Dialer{
+				Config:         httpproxy.Config{HTTPProxy: key, HTTPSProxy: key},
+				Timeout:        cfg.DialTimeout,
+				ConnectTimeout: cfg.DialTimeout,
+				DialDualStack:  true,
+			}
+			if via, err = d.GetDialFunc(false); err != nil {
+				mu.Unlock()
+				return nil, err
+			}
+			viaURLs[key] = via
+		}
+		mu.Unlock()
+		return via(a)
+	}
+}
+
+// dialTimeout — fasthttp dial без ctx, поэтому DialTimeout навешивается здесь.
+func dialTimeout(cfg config.Config, network, addr string) (net.Conn, error) {
+	ctx := context.Background()
+	if cfg.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.DialTimeout)
+		defer cancel()
+	}
+	return cfg.Dial().DialContext(ctx, network, addr)
+}
@@ -0,0 +1,33 @@
+package fiberpool
+
+import (
+	"errors"
+
+	"httpclientpool/pkg/pool"
+
+	fibercli "github.com/gofiber/fiber/v3/client"
+	"github.com/valyala/fasthttp"
+)
+
+// classify приводит ошибки fasthttp и fiber к pool.Error; остальное разберёт pool.Classify.
+// fiber-клиент не видит ctx вызывающего, поэтому ErrTimeoutOrCancel — это его RequestTimeout.
+func classify(err error) error {
+	var kind pool.Kind
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fasthttp.ErrDialTimeout):
+		kind = pool.DialTimeout
+	case errors.Is(err, fasthttp.ErrTLSHandshakeTimeout):
+		kind = pool.TLSHandshake
+	case errors.Is(err, fasthttp.ErrNoFreeConns):
+		kind = pool.Saturated
+	case errors.Is(err, fasthttp.ErrConnectionClosed):
+		kind = pool.ConnReset
+	case errors.Is(err, fasthttp.ErrTimeout), errors.Is(err, fibercli.ErrTimeoutOrCancel):
+		kind = pool.ReadTimeout
+	default:
+		return err
+	}
+	return &pool.Error{Kind: kind, Member: -1, Err: err}
+}
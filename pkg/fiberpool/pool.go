@@ -2,55 +2,187 @@ package fiberpool
 
 import (
 	"context"
+	"fmt"
 	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/members"
 	"httpclientpool/pkg/pool"
-	"httpclientpool/pkg/rr"
+	"httpclientpool/pkg/proxyconf"
+	"httpclientpool/pkg/tlsconf"
+	"net/http"
 	"sync"
 
 	fibercli "github.com/gofiber/fiber/v3/client"
+	"github.com/valyala/fasthttp"
 )
 
 var _ pool.Client = (*ClientPool)(nil)
+var _ pool.Inspector = (*ClientPool)(nil)
+var _ pool.Resizer = (*ClientPool)(nil)
+var _ pool.Warmer = (*ClientPool)(nil)
+var _ pool.Shutdowner = (*ClientPool)(nil)
 
 type ClientPool struct {
-	clients   []*fibercli.Client
-	spin      rr.RR
+	set       *members.Set[*conn]
 	cfg       config.Config
 	closeOnce sync.Once
 }
 
-func New(cfg config.Config) *ClientPool {
+func New(cfg config.Config) (*ClientPool, error) {
 	if cfg.Size <= 0 {
 		cfg.Size = config.DefaultConfig().Size
 	}
-	cs := make([]*fibercli.Client, 0, cfg.Size)
-	for i := 0; i < cfg.Size; i++ {
-		cs = append(cs, newFiberClient(cfg))
+	if cfg.InsecureSkipVerify {
+		cfg.Log().Warn("httpclientpool: TLS certificate verification is disabled", "pool", cfg.Name, "base_url", cfg.BaseURL)
 	}
-	return &ClientPool{clients: cs, cfg: cfg}
+	if cfg.Protocol != "" && cfg.Protocol != config.ProtocolHTTP1 {
+		return nil, fmt.Errorf("fiberpool: protocol %q is not supported, fasthttp speaks HTTP/1.1 only", cfg.Protocol)
+	}
+
+	tc, err := tlsconf.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pf, err := proxyconf.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := members.New(cfg, func(ep *members.Endpoint) *conn {
+		c := cfg
+		c.BaseURL = ep.URL
+		return newConn(c, tc, pf, ep.Addr)
+	}, (*conn).close)
+	if err != nil {
+		return nil, err
+	}
+	p := &ClientPool{set: set, cfg: cfg}
+	if err := set.WarmupOnNew(p.warm); err != nil {
+		set.Close()
+		return nil, err
+	}
+	if cfg.Name != "" {
+		pool.Register(cfg.Name, p)
+	}
+	return p, nil
+}
+
+// Warmup заранее устанавливает соединение каждого члена пула (см. config.Warmup)
+// и сообщает, какие члены прогреть не удалось. fasthttp не поддерживает ctx, поэтому
+// отдельный запрос прогрева ограничен RequestTimeout, а ctx — только ожидание свободного соединения.
+func (p *ClientPool) Warmup(ctx context.Context) (pool.WarmupReport, error) {
+	rep := p.set.Warmup(ctx, 0, p.warm)
+	return rep, rep.Err()
+}
+
+func (p *ClientPool) warm(_ context.Context, c *conn) error {
+	return classify(p.warmOnce(c))
+}
+
+func (p *ClientPool) warmOnce(c *conn) error {
+	if p.cfg.Warmup.Path == "" {
+		res, err := c.client.Head("")
+		if err == nil {
+			res.Close()
+		}
+		return err
+	}
+	res, err := c.client.Get(p.cfg.Warmup.Path)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+	if res.StatusCode() >= 400 {
+		return fmt.Errorf("warmup %s: status %d", p.cfg.Warmup.Path, res.StatusCode())
+	}
+	return nil
 }
 
 func (p *ClientPool) Get(ctx context.Context, path string) (pool.Response, error) {
-	i := p.spin.Next(len(p.clients))
-	res, err := p.clients[i].Get(path)
+	m, err := p.set.Acquire(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return newFiberResp(res), nil
+	res, err := m.Client.client.Get(path)
+	if err = p.set.Release(ctx, m, path, classify(err)); err != nil {
+		return nil, err
+	}
+	return p.response(res), nil
 }
 
 func (p *ClientPool) Post(ctx context.Context, path string, body any) (pool.Response, error) {
-	i := p.spin.Next(len(p.clients))
-	res, err := p.clients[i].Post(path, fibercli.Config{
-		Body: body,
+	req := pool.WithIdempotency(ctx, p.cfg.Idempotency, pool.Request{Method: http.MethodPost, Path: path})
+	m, err := p.set.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := m.Client.client.Post(path, fibercli.Config{
+		Body:   body,
+		Header: headerMap(req.Header),
 	})
+	if err = p.set.Release(ctx, m, path, classify(err)); err != nil {
+		return nil, err
+	}
+	return p.response(res), nil
+}
+
+// Do выполняет произвольный запрос напрямую через fasthttp: fiber-клиент не умеет
+// потоковое тело запроса. Body отправляется потоком, ответ читается целиком.
+func (p *ClientPool) Do(ctx context.Context, req pool.Request) (pool.Response, error) {
+	req = pool.WithIdempotency(ctx, p.cfg.Idempotency, req)
+	m, err := p.set.Acquire(ctx)
 	if err != nil {
+		req.CloseBody()
 		return nil, err
 	}
-	return newFiberResp(res), nil
+	freq := m.Client.request(req)
+	defer fasthttp.ReleaseRequest(freq)
+	resp := fasthttp.AcquireResponse()
+
+	err = m.Client.base.Do(freq, resp)
+	if err = p.set.Release(ctx, m, req.Path, classify(err)); err != nil {
+		fasthttp.ReleaseResponse(resp)
+		return nil, err
+	}
+	if p.cfg.PooledBuffers {
+		return newPooledResp(resp, func() { fasthttp.ReleaseResponse(resp) }), nil
+	}
+	defer fasthttp.ReleaseResponse(resp)
+	return newFiberResp(resp), nil
+}
+
+// response отдаёт ответ fiber-клиента: копию тела или, в режиме PooledBuffers, сам ответ,
+// который вернётся в пул fiber по Release.
+func (p *ClientPool) response(res *fibercli.Response) pool.Response {
+	if p.cfg.PooledBuffers {
+		return newPooledResp(res.RawResponse, res.Close)
+	}
+	return newFiberResp(res.RawResponse)
+}
+
+// Resize меняет число соединений пула на лету. При уменьшении лишние соединения
+// дорабатывают запросы в полёте и закрываются.
+func (p *ClientPool) Resize(n int) error {
+	return p.set.Resize(n)
+}
+
+func (p *ClientPool) Snapshot() pool.Snapshot {
+	return p.set.Snapshot("fiber", p.cfg)
+}
+
+// Shutdown — мягкая остановка для SIGTERM: новые Get/Post получают pool.ErrClosed,
+// запросы в полёте дорабатывают (или до истечения ctx), затем соединения закрываются.
+func (p *ClientPool) Shutdown(ctx context.Context) error {
+	err := p.set.Shutdown(ctx)
+	p.Close()
+	return err
 }
 
+// Close закрывает пул сразу, не дожидаясь запросов в полёте.
 func (p *ClientPool) Close() {
 	p.closeOnce.Do(func() {
+		if p.cfg.Name != "" {
+			pool.Unregister(p.cfg.Name, p)
+		}
+		p.set.Close()
 	})
 }
@@ -41,7 +41,7 @@ func TestFiberPool_GetPost(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -78,7 +78,7 @@ func TestFiberPool_ClientTimeout_Get(t *testing.T) {
 	cfg.InsecureSkipVerify = true
 	cfg.RequestTimeout = 50 * time.Millisecond
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx := context.Background()
@@ -101,7 +101,7 @@ func TestFiberPool_Parallel_NoRace(t *testing.T) {
 	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx := context.Background()
@@ -128,7 +128,7 @@ func TestFiberPool_Parallel_NoRace(t *testing.T) {
 
 func TestFiberPool_Close_Idempotent(t *testing.T) {
 	cfg := config.DefaultConfig()
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	p.Close()
 	p.Close()
 }
@@ -148,7 +148,7 @@ func TestFiberPool_BaseURL_Join(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx := context.Background()
@@ -174,7 +174,7 @@ func TestFiberPool_DefaultSize(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx := context.Background()
@@ -203,7 +203,7 @@ func TestFiberPool_DistributesAcrossConnections(t *testing.T) {
 	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx := context.Background()
@@ -227,3 +227,20 @@ func TestFiberPool_DistributesAcrossConnections(t *testing.T) {
 	}
 	t.Logf("unique TCP connections: %d", n)
 }
+
+func mustNew(t *testing.T, cfg config.Config) *ClientPool {
+	t.Helper()
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestFiberPool_RejectsHTTP2(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Protocol = config.ProtocolHTTP2
+	if _, err := New(cfg); err == nil {
+		t.Fatalf("expected error for http2 protocol")
+	}
+}
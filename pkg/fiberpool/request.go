@@ -0,0 +1,57 @@
+package fiberpool
+
+import (
+	"net/http"
+	"strings"
+
+	"httpclientpool/pkg/pool"
+
+	"github.com/valyala/fasthttp"
+)
+
+// request собирает fasthttp-запрос из pool.Request. Body уходит через SetBodyStream
+// (ContentLength 0 — chunked, как и сжатое тело); fasthttp закрывает его вместе с запросом.
+func (c *conn) request(req pool.Request) *fasthttp.Request {
+	freq := fasthttp.AcquireRequest()
+	freq.SetRequestURI(c.url(req.Path))
+	if req.Method != "" {
+		freq.Header.SetMethod(req.Method)
+	}
+	for k, vs := range req.Header {
+		for _, v := range vs {
+			freq.Header.Add(k, v)
+		}
+	}
+	if req.Body != nil {
+		if req.Header.Get("Content-Type") == "" {
+			freq.Header.SetContentType(pool.DefaultBodyContentType)
+		}
+		size := int(req.ContentLength)
+		if size <= 0 {
+			size = -1
+		}
+		body, size := c.compressBody(freq, req.Body, size)
+		freq.SetBodyStream(body, size)
+	}
+	return freq
+}
+
+// headerMap — заголовки для fibercli.Config (по одному значению на имя).
+func headerMap(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(h))
+	for k := range h {
+		m[k] = h.Get(k)
+	}
+	return m
+}
+
+// url склеивает путь с базовым URL так же, как fiber-клиент.
+func (c *conn) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.baseURL + path
+}
@@ -1,21 +1,69 @@
 package fiberpool
 
 import (
-	fibercli "github.com/gofiber/fiber/v3/client"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
 )
 
 type fiberResp struct {
 	status int
 	body   []byte
+	header http.Header
 }
 
-func newFiberResp(r *fibercli.Response) fiberResp {
+func newFiberResp(r *fasthttp.Response) fiberResp {
 	b := append([]byte(nil), r.Body()...)
 	return fiberResp{
 		status: r.StatusCode(),
 		body:   b,
+		header: header(r),
+	}
+}
+
+func header(r *fasthttp.Response) http.Header {
+	h := make(http.Header)
+	for k, v := range r.Header.All() {
+		h.Add(string(k), string(v))
+	}
+	return h
+}
+
+func (r fiberResp) StatusCode() int     { return r.status }
+func (r fiberResp) Body() []byte        { return r.body }
+func (r fiberResp) Header() http.Header { return r.header }
+
+func (r fiberResp) Release() {}
+
+// pooledResp — ответ в режиме PooledBuffers без копирования: Body указывает в буфер
+// ответа fasthttp (он из bytebufferpool), а Release возвращает ответ в пул.
+// Header собирается лениво — многим вызывающим он не нужен.
+type pooledResp struct {
+	raw     *fasthttp.Response
+	release func()
+	status  int
+	body    []byte
+	header  http.Header
+}
+
+func newPooledResp(raw *fasthttp.Response, release func()) *pooledResp {
+	return &pooledResp{raw: raw, release: release, status: raw.StatusCode(), body: raw.Body()}
+}
+
+func (r *pooledResp) StatusCode() int { return r.status }
+func (r *pooledResp) Body() []byte    { return r.body }
+
+func (r *pooledResp) Header() http.Header {
+	if r.header == nil && r.raw != nil {
+		r.header = header(r.raw)
 	}
+	return r.header
 }
 
-func (r fiberResp) StatusCode() int { return r.status }
-func (r fiberResp) Body() []byte    { return r.body }
+func (r *pooledResp) Release() {
+	if r.raw == nil {
+		return
+	}
+	r.raw, r.body = nil, nil
+	r.release()
+}
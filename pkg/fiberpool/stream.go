@@ -0,0 +1,90 @@
+package fiberpool
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"httpclientpool/pkg/compress"
+	"httpclientpool/pkg/pool"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Stream выполняет запрос напрямую через fasthttp (conn.stream): fiber-клиент копирует
+// ответ целиком. Член пула занят, пока не закрыт Body. fasthttp не видит ctx, поэтому
+// ctx ограничивает только ожидание свободного соединения, а весь обмен — RequestTimeout.
+func (p *ClientPool) Stream(ctx context.Context, req pool.Request) (*pool.StreamResponse, error) {
+	req = pool.WithIdempotency(ctx, p.cfg.Idempotency, req)
+	m, err := p.set.Acquire(ctx)
+	if err != nil {
+		req.CloseBody()
+		return nil, err
+	}
+	freq := m.Client.request(req)
+	defer fasthttp.ReleaseRequest(freq)
+
+	resp := fasthttp.AcquireResponse()
+	if err := m.Client.stream.Do(freq, resp); err != nil {
+		fasthttp.ReleaseResponse(resp)
+		return nil, p.set.Release(ctx, m, req.Path, classify(err))
+	}
+
+	h := header(resp)
+	enc, limit := m.Client.decodeStream(resp, h)
+	return &pool.StreamResponse{
+		StatusCode: resp.StatusCode(),
+		Header:     h,
+		Body:       p.set.Hold(ctx, m, req.Path, &streamBody{resp: resp, enc: enc, limit: limit}),
+	}, nil
+}
+
+// streamBody читает тело из соединения и, если enc задан, распаковывает его не больше
+// чем в limit байт. Close возвращает соединение и ответ fasthttp в пулы.
+type streamBody struct {
+	resp  *fasthttp.Response
+	enc   string
+	limit int64
+	r     io.Reader
+	dec   io.ReadCloser
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	if b.r == nil {
+		b.r = b.resp.BodyStream()
+		if b.r == nil {
+			// Тела нет (HEAD, 204, 304).
+			b.r = bytes.NewReader(b.resp.Body())
+		}
+		if b.enc != "" {
+			dec, err := compress.NewReader(b.enc, b.r, b.limit)
+			if err != nil {
+				b.r = errReader{err}
+				return 0, err
+			}
+			b.dec, b.r = dec, dec
+		}
+	}
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = classify(err)
+	}
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	if b.resp == nil {
+		return nil
+	}
+	if b.dec != nil {
+		_ = b.dec.Close()
+	}
+	err := b.resp.CloseBodyStream()
+	fasthttp.ReleaseResponse(b.resp)
+	b.resp = nil
+	return err
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
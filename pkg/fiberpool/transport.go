@@ -0,0 +1,150 @@
+package fiberpool
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"httpclientpool/pkg/compress"
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+
+	"github.com/valyala/bytebufferpool"
+	"github.com/valyala/fasthttp"
+)
+
+// transport — fasthttp.RoundTripper членов пула: сжатие (compress.go) и лимиты тела ответа.
+// fasthttp сам ответы не распаковывает и не отделяет чтение тела от чтения заголовков,
+// поэтому при BodyReadTimeout тело читается потоком поверх fasthttp.DefaultTransport.
+type transport struct {
+	cm     config.Compression
+	accept string
+	// stream — транспорт conn.stream: тело ответа ещё не прочитано, лимиты не действуют.
+	stream      bool
+	maxBody     int64
+	bodyTimeout time.Duration
+}
+
+// withTransport ставит transport обоим fasthttp-клиентам члена пула, если настроено
+// сжатие или лимиты тела. Без BodyReadTimeout MaxResponseBodySize проверяет сам fasthttp;
+// с ним fasthttp отдаёт тело потоком (см. readBody), а dial помечает соединения (bodyConn).
+func (c *conn) withTransport(cfg config.Config) {
+	cm := cfg.Compression
+	if len(cm.Accept) == 0 && cm.Request == "" && cfg.MaxResponseBodySize <= 0 && cfg.BodyReadTimeout <= 0 {
+		return
+	}
+	c.tr = &transport{
+		cm:          cm,
+		accept:      compress.AcceptEncoding(cm),
+		maxBody:     cfg.MaxResponseBodySize,
+		bodyTimeout: cfg.BodyReadTimeout,
+	}
+	c.base.Transport = c.tr
+	c.base.MaxResponseBodySize = int(cfg.MaxResponseBodySize)
+	if cfg.BodyReadTimeout > 0 {
+		// Тело длиннее MaxResponseBodySize при StreamBody fasthttp не читает, а отдаёт потоком:
+		// вместе с заголовками читается не больше байта.
+		c.base.MaxResponseBodySize = 1
+		dial := c.base.Dial
+		c.base.Dial = func(addr string) (net.Conn, error) {
+			nc, err := dial(addr)
+			if err != nil {
+				return nil, err
+			}
+			return bodyConn{nc}, nil
+		}
+	}
+	c.stream.Transport = &transport{cm: cm, accept: c.tr.accept, stream: true}
+}
+
+func (t *transport) RoundTrip(hc *fasthttp.HostClient, req *fasthttp.Request, resp *fasthttp.Response) (bool, error) {
+	if err := t.compressRequest(req); err != nil {
+		return false, err
+	}
+	if t.stream {
+		return fasthttp.DefaultTransport.RoundTrip(hc, req, resp)
+	}
+
+	start := time.Now()
+	resp.StreamBody = t.bodyTimeout > 0
+	retry, err := fasthttp.DefaultTransport.RoundTrip(hc, req, resp)
+	if err == nil && resp.StreamBody {
+		err = t.readBody(hc, resp, start)
+	}
+	if errors.Is(err, fasthttp.ErrBodyTooLarge) {
+		return false, &pool.BodyTooLargeError{Limit: t.maxBody}
+	}
+	if err != nil {
+		return retry, err
+	}
+	return false, t.decodeResponse(resp)
+}
+
+// readBody дочитывает тело, которое fasthttp отдал потоком: дедлайн чтения соединения
+// сдвигается на BodyReadTimeout (но не дальше ReadTimeout), размер ограничен maxBody.
+// Соединение, на котором тело не дочитано, fasthttp закрывает сам (BodyWriteTo).
+func (t *transport) readBody(hc *fasthttp.HostClient, resp *fasthttp.Response, start time.Time) error {
+	resp.StreamBody = false
+	if resp.BodyStream() == nil {
+		return nil
+	}
+
+	bodyDeadline := false
+	if a, ok := resp.LocalAddr().(connAddr); ok {
+		bd := time.Now().Add(t.bodyTimeout)
+		if hc.ReadTimeout <= 0 || bd.Before(start.Add(hc.ReadTimeout)) {
+			bodyDeadline = a.conn.SetReadDeadline(bd) == nil
+		}
+	}
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	err := resp.BodyWriteTo(&limitWriter{buf: buf, limit: t.maxBody})
+	var ne net.Error
+	if bodyDeadline && errors.As(err, &ne) && ne.Timeout() {
+		return pool.ErrBodyReadTimeout
+	}
+	if err != nil {
+		return err
+	}
+	resp.SetBody(buf.B)
+	return nil
+}
+
+// limitWriter собирает тело в buf, пока оно не длиннее limit (0 — без лимита).
+type limitWriter struct {
+	buf   *bytebufferpool.ByteBuffer
+	limit int64
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && int64(len(w.buf.B)+len(p)) > w.limit {
+		return 0, fasthttp.ErrBodyTooLarge
+	}
+	return w.buf.Write(p)
+}
+
+// bodyConn — соединение члена пула при BodyReadTimeout. fasthttp не отдаёт соединение
+// транспорту после RoundTrip, но запоминает LocalAddr в ответе: через connAddr readBody
+// ставит дедлайн на тело. tls.Conn отдаёт LocalAddr нижнего соединения.
+type bodyConn struct{ net.Conn }
+
+func (c bodyConn) LocalAddr() net.Addr { return connAddr{addr: c.Conn.LocalAddr(), conn: c.Conn} }
+
+type connAddr struct {
+	addr net.Addr
+	conn net.Conn
+}
+
+func (a connAddr) Network() string {
+	if a.addr == nil {
+		return ""
+	}
+	return a.addr.Network()
+}
+
+func (a connAddr) String() string {
+	if a.addr == nil {
+		return ""
+	}
+	return a.addr.String()
+}
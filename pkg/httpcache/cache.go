@@ -0,0 +1,294 @@
+// Package httpcache — кэш ответов на GET в духе RFC 9111 перед любым pool.Client:
+// Cache-Control (max-age, no-store, no-cache, must-revalidate, stale-while-revalidate),
+// Expires, Vary и условная перепроверка по ETag и Last-Modified. Кэш частный
+// (private): ответы не делятся между пользователями, s-maxage и public не учитываются.
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"httpclientpool/pkg/pool"
+)
+
+var _ pool.Client = (*Client)(nil)
+
+// Options — настройки кэша.
+type Options struct {
+	// Store — хранилище ответов. По умолчанию NewLRU(DefaultMaxBytes).
+	Store Store
+}
+
+// Client — pool.Client с кэшем. Кэшируются Get и Do с методом GET без тела; Post, Stream
+// и остальные методы идут мимо кэша, а успешные небезопасные запросы (POST, PUT, PATCH,
+// DELETE) удаляют сохранённый ответ на свой путь.
+type Client struct {
+	next  pool.Client
+	store Store
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+	closed       bool
+	bg           sync.WaitGroup
+}
+
+// New ставит кэш перед next. Close закрывает и next.
+func New(next pool.Client, opts Options) *Client {
+	if opts.Store == nil {
+		opts.Store = NewLRU(DefaultMaxBytes)
+	}
+	return &Client{next: next, store: opts.Store, revalidating: map[string]bool{}}
+}
+
+func (c *Client) Get(ctx context.Context, path string) (pool.Response, error) {
+	return c.get(ctx, pool.Request{Method: http.MethodGet, Path: path})
+}
+
+func (c *Client) Post(ctx context.Context, path string, body any) (pool.Response, error) {
+	resp, err := c.next.Post(ctx, path, body)
+	c.invalidate(http.MethodPost, path, resp, err)
+	return resp, err
+}
+
+func (c *Client) Do(ctx context.Context, req pool.Request) (pool.Response, error) {
+	if cacheable(req) {
+		return c.get(ctx, req)
+	}
+	resp, err := c.next.Do(ctx, req)
+	c.invalidate(req.Method, req.Path, resp, err)
+	return resp, err
+}
+
+func (c *Client) Stream(ctx context.Context, req pool.Request) (*pool.StreamResponse, error) {
+	return c.next.Stream(ctx, req)
+}
+
+// Close дожидается фоновых перепроверок stale-while-revalidate и закрывает next.
+// Новые фоновые перепроверки после Close не запускаются.
+func (c *Client) Close() {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.bg.Wait()
+	c.next.Close()
+}
+
+// cacheable — запрос, который может быть обслужен из кэша. Условные и Range-запросы
+// вызывающего идут мимо: их ответ — не полное представление ресурса.
+func cacheable(req pool.Request) bool {
+	if (req.Method != "" && req.Method != http.MethodGet) || req.Body != nil {
+		return false
+	}
+	for _, h := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(h) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func key(req pool.Request) string { return req.Path }
+
+// maxVariants — сколько вариантов ответа по Vary хранится на один путь.
+const maxVariants = 8
+
+func (c *Client) get(ctx context.Context, req pool.Request) (pool.Response, error) {
+	reqCC := parseCacheControl(req.Header)
+	if reqCC.has("no-store") {
+		return c.fetch(ctx, req)
+	}
+	k := key(req)
+	var e *Entry
+	if stored, ok := c.store.Get(k); ok {
+		e = stored.variant(req.Header)
+	}
+	if e == nil {
+		return c.fetchAndStore(ctx, req, k, nil)
+	}
+
+	now := time.Now()
+	cc := parseCacheControl(e.Header)
+	age, lifetime := e.age(now), freshnessLifetime(e.Header, cc)
+	revalidate := reqCC.has("no-cache") || cc.has("no-cache")
+	if !revalidate && age < lifetime {
+		return newResp(e, age), nil
+	}
+	if swr, ok := cc.seconds("stale-while-revalidate"); ok && !revalidate && !cc.has("must-revalidate") && age < lifetime+swr {
+		c.revalidateAsync(ctx, req, k, e)
+		return newResp(e, age), nil
+	}
+	return c.fetchAndStore(ctx, req, k, e)
+}
+
+func (c *Client) fetch(ctx context.Context, req pool.Request) (pool.Response, error) {
+	if len(req.Header) == 0 {
+		return c.next.Get(ctx, req.Path)
+	}
+	return c.next.Do(ctx, req)
+}
+
+// fetchAndStore запрашивает ответ (при сохранённом e — условно) и сохраняет его, если можно.
+func (c *Client) fetchAndStore(ctx context.Context, req pool.Request, k string, e *Entry) (pool.Response, error) {
+	if e != nil && hasValidators(e.Header) {
+		h := req.Header.Clone()
+		if h == nil {
+			h = http.Header{}
+		}
+		if etag := e.Header.Get("ETag"); etag != "" {
+			h.Set("If-None-Match", etag)
+		}
+		if lm := e.Header.Get("Last-Modified"); lm != "" {
+			h.Set("If-Modified-Since", lm)
+		}
+		req.Header = h
+	}
+
+	sent := time.Now()
+	resp, err := c.fetch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	received := time.Now()
+
+	if resp.StatusCode() == http.StatusNotModified && e != nil {
+		e = updated(e, resp.Header(), sent, received)
+		resp.Release()
+		c.put(k, req.Header, e)
+		return newResp(e, e.age(received)), nil
+	}
+	if stored := newEntry(req, resp, sent, received); stored != nil {
+		c.put(k, req.Header, stored)
+	} else if e != nil {
+		c.put(k, req.Header, nil)
+	}
+	return resp, nil
+}
+
+// put сохраняет под ключом k вариант e ответа на запрос с заголовками h: он заменяет
+// вариант для тех же значений Vary, самые старые сверх maxVariants вытесняются.
+// Ответ без Vary заменяет все варианты. e == nil удаляет вариант для h.
+func (c *Client) put(k string, h http.Header, e *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var vs []*Entry
+	if e != nil {
+		vs = append(vs, e)
+	}
+	if old, ok := c.store.Get(k); ok && (e == nil || e.Vary != nil) {
+		head := *old
+		head.Variants = nil
+		for _, v := range append([]*Entry{&head}, old.Variants...) {
+			if !v.matches(h) && len(vs) < maxVariants {
+				vs = append(vs, v)
+			}
+		}
+	}
+	if len(vs) == 0 {
+		c.store.Delete(k)
+		return
+	}
+	head := *vs[0]
+	head.Variants = vs[1:]
+	c.store.Set(k, &head)
+}
+
+// revalidateAsync перепроверяет e в фоне (не больше одной перепроверки на ключ).
+// ctx вызывающего не отменяет её: ответ уже отдан из кэша.
+func (c *Client) revalidateAsync(ctx context.Context, req pool.Request, k string, e *Entry) {
+	c.mu.Lock()
+	if c.closed || c.revalidating[k] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[k] = true
+	c.bg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.bg.Done()
+		if resp, err := c.fetchAndStore(context.WithoutCancel(ctx), req, k, e); err == nil {
+			resp.Release()
+		}
+		c.mu.Lock()
+		delete(c.revalidating, k)
+		c.mu.Unlock()
+	}()
+}
+
+// invalidate удаляет сохранённый ответ после успешного небезопасного запроса (RFC 9111, 4.4).
+func (c *Client) invalidate(method, path string, resp pool.Response, err error) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, "":
+		return
+	}
+	if err == nil && resp.StatusCode() < 400 {
+		// Под c.mu, как и put: иначе удаление попадёт между Get и Set в put и
+		// удалённые варианты вернутся.
+		c.mu.Lock()
+		c.store.Delete(path)
+		c.mu.Unlock()
+	}
+}
+
+// newEntry копирует ответ в Entry или возвращает nil, если его нельзя сохранить.
+func newEntry(req pool.Request, resp pool.Response, sent, received time.Time) *Entry {
+	h := resp.Header()
+	cc := parseCacheControl(h)
+	if !cacheableStatus(resp.StatusCode()) || cc.has("no-store") ||
+		(freshnessLifetime(h, cc) <= 0 && !hasValidators(h)) {
+		return nil
+	}
+	e := &Entry{
+		StatusCode:   resp.StatusCode(),
+		Header:       h.Clone(),
+		Body:         append([]byte(nil), resp.Body()...),
+		RequestTime:  sent,
+		ResponseTime: received,
+	}
+	for _, name := range varyNames(h) {
+		if name == "*" {
+			return nil
+		}
+		if e.Vary == nil {
+			e.Vary = http.Header{}
+		}
+		e.Vary[name] = req.Header.Values(name)
+	}
+	return e
+}
+
+// updated — e с заголовками из ответа 304 (RFC 9111, 4.3.4) и новым временем ответа.
+func updated(e *Entry, h http.Header, sent, received time.Time) *Entry {
+	n := *e
+	n.Header = e.Header.Clone()
+	n.Variants = nil
+	for k, vs := range h {
+		if k == "Content-Length" {
+			continue
+		}
+		n.Header[k] = vs
+	}
+	n.RequestTime, n.ResponseTime = sent, received
+	return &n
+}
+
+// cachedResp — ответ из кэша: у каждого вызывающего свои копии тела и заголовков.
+type cachedResp struct {
+	status int
+	body   []byte
+	header http.Header
+}
+
+func newResp(e *Entry, age time.Duration) cachedResp {
+	h := e.Header.Clone()
+	h.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return cachedResp{status: e.StatusCode, body: append([]byte(nil), e.Body...), header: h}
+}
+
+func (r cachedResp) StatusCode() int     { return r.status }
+func (r cachedResp) Body() []byte        { return r.body }
+func (r cachedResp) Header() http.Header { return r.header }
+func (r cachedResp) Release()            {}
@@ -0,0 +1,128 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl — директивы Cache-Control: имя в нижнем регистре → аргумент ("" без аргумента).
+type cacheControl map[string]string
+
+func parseCacheControl(h http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, line := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(line, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			name, arg, _ := strings.Cut(d, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds — аргумент директивы в секундах; ok = false, если директивы нет или аргумент битый.
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	arg, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// cacheableStatus — статусы, кэшируемые по умолчанию (RFC 9110, 15.1).
+func cacheableStatus(code int) bool {
+	switch code {
+	case 200, 203, 204, 300, 301, 308, 404, 405, 410, 414, 501:
+		return true
+	}
+	return false
+}
+
+// freshnessLifetime — max-age, иначе Expires − Date (RFC 9111, 4.2.1). Эвристическая
+// свежесть не вычисляется: ответ без них сохраняется, только если его можно перепроверить.
+func freshnessLifetime(h http.Header, cc cacheControl) time.Duration {
+	if d, ok := cc.seconds("max-age"); ok {
+		return d
+	}
+	exp := h.Get("Expires")
+	if exp == "" {
+		return 0
+	}
+	expires, err := http.ParseTime(exp)
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	return max(expires.Sub(date), 0)
+}
+
+// age — текущий возраст ответа (RFC 9111, 4.2.3).
+func (e *Entry) age(now time.Time) time.Duration {
+	var apparent time.Duration
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		apparent = max(e.ResponseTime.Sub(date), 0)
+	}
+	var ageValue time.Duration
+	if n, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && n > 0 {
+		ageValue = time.Duration(n) * time.Second
+	}
+	corrected := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	return max(apparent, corrected) + now.Sub(e.ResponseTime)
+}
+
+func hasValidators(h http.Header) bool {
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// varyNames — заголовки из Vary ответа; "*" — ответ не годится ни для какого другого запроса.
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for _, n := range strings.Split(line, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				names = append(names, http.CanonicalHeaderKey(n))
+			}
+		}
+	}
+	return names
+}
+
+// variant — сохранённый вариант ответа для запроса с заголовками h или nil.
+func (e *Entry) variant(h http.Header) *Entry {
+	if e.matches(h) {
+		return e
+	}
+	for _, v := range e.Variants {
+		if v.matches(h) {
+			return v
+		}
+	}
+	return nil
+}
+
+// matches сообщает, подходит ли сохранённый ответ запросу с заголовками h по Vary.
+func (e *Entry) matches(h http.Header) bool {
+	for name, want := range e.Vary {
+		if strings.Join(h.Values(name), ", ") != strings.Join(want, ", ") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package httpcache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry — сохранённый ответ на GET.
+type Entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Vary — значения заголовков запроса, перечисленных в Vary ответа: запрос
+	// с другими значениями этот ответ не получает.
+	Vary http.Header
+	// Variants — другие варианты ответа на тот же путь (с другими значениями Vary),
+	// от недавно сохранённых к старым. У самих вариантов Variants пуст.
+	Variants []*Entry
+	// RequestTime и ResponseTime — когда ушёл запрос и пришёл ответ (RFC 9111, 4.2.3).
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+// Size — оценка занимаемой памяти: тело и заголовки.
+func (e *Entry) Size() int64 {
+	n := int64(len(e.Body))
+	for _, h := range []http.Header{e.Header, e.Vary} {
+		for k, vs := range h {
+			n += int64(len(k))
+			for _, v := range vs {
+				n += int64(len(v))
+			}
+		}
+	}
+	for _, v := range e.Variants {
+		n += v.Size()
+	}
+	return n
+}
+
+// Store — хранилище ответов. Entry после Set не меняется: обновление — новый Set.
+// Реализации должны быть безопасны для параллельного использования.
+type Store interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, e *Entry)
+	Delete(key string)
+}
+
+// DefaultMaxBytes — размер LRU по умолчанию.
+const DefaultMaxBytes = 64 << 20
+
+// LRU — Store в памяти с пределом суммарного Entry.Size: при переполнении вытесняются
+// давно не читанные ответы. Ответ больше предела не сохраняется.
+type LRU struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *Entry
+	size  int64
+}
+
+// NewLRU создаёт LRU на maxBytes байт (<= 0 — DefaultMaxBytes).
+func NewLRU(maxBytes int64) *LRU {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &LRU{maxBytes: maxBytes, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+func (c *LRU) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+func (c *LRU) Set(key string, e *Entry) {
+	size := e.Size()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	if size > c.maxBytes {
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruItem{key: key, entry: e, size: size})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Len — число ответов в кэше.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Bytes — суммарный Entry.Size ответов в кэше.
+func (c *LRU) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *LRU) remove(el *list.Element) {
+	it := c.ll.Remove(el).(*lruItem)
+	delete(c.items, it.key)
+	c.size -= it.size
+}
@@ -0,0 +1,52 @@
+package httpcache_test
+
+import (
+	"strings"
+	"testing"
+
+	"httpclientpool/pkg/httpcache"
+)
+
+func entry(n int) *httpcache.Entry {
+	return &httpcache.Entry{StatusCode: 200, Body: []byte(strings.Repeat("x", n))}
+}
+
+func TestLRU_Evicts(t *testing.T) {
+	c := httpcache.NewLRU(100)
+	c.Set("a", entry(40))
+	c.Set("b", entry(40))
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a must be cached")
+	}
+	// a прочитан последним, вытесняется b.
+	c.Set("c", entry(40))
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("b must be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a must survive")
+	}
+	if c.Len() != 2 || c.Bytes() != 80 {
+		t.Fatalf("got %d entries, %d bytes", c.Len(), c.Bytes())
+	}
+}
+
+func TestLRU_ReplaceAndOversize(t *testing.T) {
+	c := httpcache.NewLRU(100)
+	c.Set("a", entry(10))
+	c.Set("a", entry(30))
+	if c.Len() != 1 || c.Bytes() != 30 {
+		t.Fatalf("replace: got %d entries, %d bytes", c.Len(), c.Bytes())
+	}
+
+	c.Set("a", entry(101))
+	if _, ok := c.Get("a"); ok || c.Bytes() != 0 {
+		t.Fatal("entry over the cap must not be stored and must drop the old one")
+	}
+
+	c.Set("b", entry(10))
+	c.Delete("b")
+	if c.Len() != 0 {
+		t.Fatal("delete")
+	}
+}
@@ -0,0 +1,113 @@
+package members
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+// load — общая загрузка пула для автоскейлера: запросы в полёте (включая ждущих
+// соединение), их пик с последнего снятия и суммарное ожидание свободного соединения.
+type load struct {
+	busy     atomic.Int64
+	peak     atomic.Int64
+	acquired atomic.Int64
+	waitNs   atomic.Int64
+}
+
+func (l *load) begin() {
+	n := l.busy.Add(1)
+	for {
+		p := l.peak.Load()
+		if n <= p || l.peak.CompareAndSwap(p, n) {
+			return
+		}
+	}
+}
+
+func (l *load) end() { l.busy.Add(-1) }
+
+func (l *load) waited(d time.Duration) {
+	l.acquired.Add(1)
+	l.waitNs.Add(int64(d))
+}
+
+// sample возвращает пик занятости и среднее ожидание с прошлого вызова.
+func (l *load) sample(prevAcquired, prevWait *int64) (peak int64, avgWait time.Duration) {
+	peak = l.peak.Swap(l.busy.Load())
+	acq, wait := l.acquired.Load(), l.waitNs.Load()
+	if n := acq - *prevAcquired; n > 0 {
+		avgWait = time.Duration((wait - *prevWait) / n)
+	}
+	*prevAcquired, *prevWait = acq, wait
+	return peak, avgWait
+}
+
+// autoscaleDefaults подставляет значения по умолчанию в нулевые Interval, Min и Max:
+// конфиг, собранный в коде, не проходит через загрузчик и Validate. Max не ниже Min.
+func autoscaleDefaults(a config.Autoscale) config.Autoscale {
+	d := config.DefaultConfig().Autoscale
+	if a.Interval <= 0 {
+		a.Interval = d.Interval
+	}
+	if a.Min < 1 {
+		a.Min = d.Min
+	}
+	if a.Max <= 0 {
+		a.Max = d.Max
+	}
+	a.Max = max(a.Max, a.Min)
+	return a
+}
+
+// autoscale раз в Interval сверяет размер пула с загрузкой. Растёт в полтора раза, если
+// занятость упёрлась в ёмкость пула или среднее ожидание соединения выше MaxWait.
+// Сжимается до пиковой занятости за ScaleDownCooldown, если она была ниже размера.
+func (s *Set[C]) autoscale(ctx context.Context) {
+	defer s.wg.Done()
+	a := s.cfg.Autoscale
+	t := time.NewTicker(a.Interval)
+	defer t.Stop()
+
+	var prevAcquired, prevWait int64
+	// Окно для сжатия: пик занятости с windowStart. Сбрасывается после каждого решения.
+	var windowPeak int64
+	windowStart, lastChange := time.Now(), time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		peak, avgWait := s.load.sample(&prevAcquired, &prevWait)
+		windowPeak = max(windowPeak, peak)
+		size := s.Size()
+
+		want := size
+		saturated := peak >= int64(size*s.capacity) || a.MaxWait > 0 && avgWait > a.MaxWait
+		switch {
+		case saturated:
+			if size < a.Max && time.Since(lastChange) >= a.ScaleUpCooldown {
+				want = min(a.Max, size+max(1, size/2))
+			}
+		case time.Since(windowStart) >= a.ScaleDownCooldown:
+			need := int((windowPeak + int64(s.capacity) - 1) / int64(s.capacity))
+			want = min(size, max(a.Min, need))
+			windowPeak, windowStart = 0, time.Now()
+		}
+		if want == size {
+			continue
+		}
+
+		if err := s.Resize(want); err != nil {
+			return
+		}
+		s.cfg.Log().Info("httpclientpool: autoscaled", "pool", s.cfg.Name, "from", size, "to", want,
+			"peak_in_flight", peak, "avg_wait", avgWait)
+		lastChange = time.Now()
+		windowPeak, windowStart = 0, lastChange
+	}
+}
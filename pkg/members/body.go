@@ -0,0 +1,52 @@
+package members
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"httpclientpool/pkg/pool"
+)
+
+// Hold держит член пула занятым, пока вызывающий не закроет тело потокового ответа.
+// Ошибки чтения (кроме io.EOF) приводятся к *pool.Error и попадают в статистику члена.
+func (s *Set[C]) Hold(ctx context.Context, m *Member[C], path string, body io.ReadCloser) io.ReadCloser {
+	return &heldBody{rc: body, release: func(err error) { s.Release(ctx, m, path, err) }, ctx: ctx, member: m.ID}
+}
+
+type heldBody struct {
+	rc      io.ReadCloser
+	release func(err error)
+	ctx     context.Context
+	member  int
+
+	once sync.Once
+	// Close может прийти из другой горутины, чтобы прервать чтение.
+	mu  sync.Mutex
+	err error
+}
+
+func (b *heldBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = pool.NewError(b.ctx, b.member, err)
+		b.mu.Lock()
+		if b.err == nil {
+			b.err = err
+		}
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+func (b *heldBody) Close() error {
+	err := b.rc.Close()
+	b.once.Do(func() {
+		b.mu.Lock()
+		rerr := b.err
+		b.mu.Unlock()
+		b.release(rerr)
+	})
+	return err
+}
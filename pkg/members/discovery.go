@@ -0,0 +1,73 @@
+package members
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+// firstDiscovery ждёт первый набор эндпоинтов от Discovery, чтобы пул не стартовал пустым.
+// Если он не пришёл за lookupTimeout, пул стартует с эндпоинтами из конфига.
+func (s *Set[C]) firstDiscovery(ctx context.Context, updates <-chan []config.Endpoint, fallback []Spec) []Spec {
+	timer := time.NewTimer(s.lookupTimeout())
+	defer timer.Stop()
+	for {
+		select {
+		case eps, ok := <-updates:
+			if !ok {
+				return fallback
+			}
+			if len(eps) == 0 {
+				continue
+			}
+			return toSpecs(eps)
+		case <-timer.C:
+			s.cfg.Log().Warn("httpclientpool: no endpoints from discovery yet, starting with configured ones", "pool", s.cfg.Name)
+			return fallback
+		case <-ctx.Done():
+			return fallback
+		}
+	}
+}
+
+func (s *Set[C]) watchDiscovery(ctx context.Context, updates <-chan []config.Endpoint) {
+	defer s.wg.Done()
+	for {
+		var eps []config.Endpoint
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+			eps = u
+		}
+		if len(eps) == 0 {
+			s.cfg.Log().Warn("httpclientpool: discovery returned no endpoints, keeping current members", "pool", s.cfg.Name)
+			continue
+		}
+
+		base := toSpecs(eps)
+		specs := base
+		if s.cfg.DNSRefresh > 0 {
+			lctx, cancel := context.WithTimeout(ctx, s.lookupTimeout())
+			resolved, err := s.resolve(lctx, base)
+			cancel()
+			if err == nil {
+				specs = resolved
+			} else if ctx.Err() == nil {
+				s.cfg.Log().Warn("httpclientpool: DNS resolution of discovered endpoints failed", "pool", s.cfg.Name, "err", err)
+			}
+		}
+
+		s.mu.Lock()
+		s.base = base
+		if !slices.Equal(specs, s.specs) {
+			s.apply(specs, s.size)
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,117 @@
+package members
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+func (s *Set[C]) resolver() config.Resolver {
+	if s.cfg.Resolver != nil {
+		return s.cfg.Resolver
+	}
+	return net.DefaultResolver
+}
+
+// resolve разворачивает каждый эндпоинт в набор адресов его хоста. Адрес наследует вес эндпоинта.
+func (s *Set[C]) resolve(ctx context.Context, base []Spec) ([]Spec, error) {
+	var out []Spec
+	for _, sp := range base {
+		u, err := url.Parse(sp.URL)
+		if err != nil {
+			return nil, err
+		}
+		host := u.Hostname()
+		if host == "" || net.ParseIP(host) != nil {
+			out = append(out, sp)
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+		}
+
+		addrs, err := s.resolver().LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("dns: no addresses for %s", host)
+		}
+		slices.Sort(addrs)
+		for _, a := range addrs {
+			out = append(out, Spec{URL: sp.URL, Addr: net.JoinHostPort(a, port), Weight: sp.Weight})
+		}
+	}
+	return out, nil
+}
+
+func (s *Set[C]) lookupTimeout() time.Duration {
+	if s.cfg.DialTimeout > 0 {
+		return s.cfg.DialTimeout
+	}
+	return 5 * time.Second
+}
+
+// initialDNS резолвит эндпоинты при создании пула. При ошибке пул стартует с обычным
+// dial по хосту из URL и переключится на адреса при следующем успешном обновлении.
+func (s *Set[C]) initialDNS(ctx context.Context, base []Spec) []Spec {
+	ctx, cancel := context.WithTimeout(ctx, s.lookupTimeout())
+	defer cancel()
+	specs, err := s.resolve(ctx, base)
+	if err != nil {
+		s.cfg.Log().Warn("httpclientpool: initial DNS resolution failed", "pool", s.cfg.Name, "err", err)
+		return base
+	}
+	return specs
+}
+
+func (s *Set[C]) watchDNS(ctx context.Context) {
+	defer s.wg.Done()
+	t := time.NewTicker(s.cfg.DNSRefresh)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		s.mu.Lock()
+		base := s.base
+		s.mu.Unlock()
+
+		lctx, cancel := context.WithTimeout(ctx, s.lookupTimeout())
+		specs, err := s.resolve(lctx, base)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				s.cfg.Log().Warn("httpclientpool: DNS refresh failed, keeping current members", "pool", s.cfg.Name, "err", err)
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		// Discovery мог сменить base, пока мы резолвили, — тогда этот результат устарел.
+		if slices.Equal(base, s.base) {
+			// Адресов больше, чем членов: сдвигаем распределение, чтобы трафик со временем
+			// получил каждый адрес, а не только первые по порядку.
+			crowded := len(dedupe(specs)) > s.size
+			if crowded {
+				s.offset += s.size
+			}
+			if crowded || !slices.Equal(specs, s.specs) {
+				s.apply(specs, s.size)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,120 @@
+package members
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+// Endpoint — базовый URL, между которыми распределяются члены пула, и его пассивный health-check:
+// после threshold транспортных ошибок подряд эндпоинт выключается на cooldown.
+// По истечении cooldown он снова получает трафик; первая же ошибка выключает его повторно,
+// первый успех — сбрасывает счётчик.
+type Endpoint struct {
+	URL string
+	// Addr — адрес (ip:port), куда dial'ит член пула вместо хоста из URL. Пусто — обычный dial.
+	Addr   string
+	Weight int
+
+	threshold int64
+	cooldown  time.Duration
+	failures  atomic.Int64
+	downUntil atomic.Int64
+}
+
+func (e *Endpoint) key() string { return Spec{URL: e.URL, Addr: e.Addr}.key() }
+
+func (e *Endpoint) Healthy() bool {
+	return time.Now().UnixNano() >= e.downUntil.Load()
+}
+
+func (e *Endpoint) observe(err error) {
+	if err == nil {
+		e.failures.Store(0)
+		return
+	}
+	if n := e.failures.Add(1); e.threshold > 0 && n >= e.threshold {
+		e.downUntil.Store(time.Now().Add(e.cooldown).UnixNano())
+	}
+}
+
+func (e *Endpoint) snapshot(members int) pool.EndpointSnapshot {
+	es := pool.EndpointSnapshot{
+		URL:                 config.RedactURL(e.URL),
+		Addr:                e.Addr,
+		Weight:              e.Weight,
+		Members:             members,
+		Healthy:             e.Healthy(),
+		ConsecutiveFailures: int(e.failures.Load()),
+	}
+	if ns := e.downUntil.Load(); ns != 0 {
+		es.DownUntil = time.Unix(0, ns)
+	}
+	return es
+}
+
+// Spread делит n членов пула между эндпоинтами пропорционально весам (метод наибольших остатков).
+// Если n не меньше числа эндпоинтов, каждый получает хотя бы одного члена.
+func Spread(weights []int, n int) []int {
+	counts := make([]int, len(weights))
+	if len(weights) == 0 || n <= 0 {
+		return counts
+	}
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	assigned := 0
+	rem := make([]int, len(weights))
+	for i, w := range weights {
+		counts[i] = n * w / total
+		rem[i] = n * w % total
+		assigned += counts[i]
+	}
+	for ; assigned < n; assigned++ {
+		best := 0
+		for i := range rem {
+			if rem[i] > rem[best] {
+				best = i
+			}
+		}
+		counts[best]++
+		rem[best] = -1
+	}
+
+	if n >= len(weights) {
+		for i := range counts {
+			if counts[i] > 0 {
+				continue
+			}
+			donor := 0
+			for j := range counts {
+				if counts[j] > counts[donor] {
+					donor = j
+				}
+			}
+			counts[donor]--
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// spreadFrom — Spread, в котором при ничьей члены достаются эндпоинтам начиная с off
+// (по кругу), а не с первого.
+func spreadFrom(weights []int, n, off int) []int {
+	if len(weights) == 0 {
+		return Spread(weights, n)
+	}
+	off %= len(weights)
+	rotated := append(slices.Clone(weights[off:]), weights[:off]...)
+	counts := make([]int, len(weights))
+	for i, c := range Spread(rotated, n) {
+		counts[(i+off)%len(weights)] = c
+	}
+	return counts
+}
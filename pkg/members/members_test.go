@@ -0,0 +1,464 @@
+package members_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/members"
+	"httpclientpool/pkg/pool"
+)
+
+func TestSpread(t *testing.T) {
+	cases := []struct {
+		weights []int
+		n       int
+		want    []int
+	}{
+		{[]int{1}, 8, []int{8}},
+		{[]int{1, 1, 2}, 8, []int{2, 2, 4}},
+		{[]int{1, 1, 1}, 8, []int{3, 3, 2}},
+		{[]int{10, 1}, 4, []int{3, 1}},
+		{[]int{1, 1, 1}, 2, []int{1, 1, 0}},
+	}
+	for _, c := range cases {
+		if got := members.Spread(c.weights, c.n); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Spread(%v, %d) = %v, want %v", c.weights, c.n, got, c.want)
+		}
+	}
+}
+
+func newSet(t *testing.T, cfg config.Config) *members.Set[string] {
+	t.Helper()
+	s, err := members.New(cfg, func(ep *members.Endpoint) string { return ep.URL }, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSet_Failover(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Size = 4
+	cfg.Endpoints = []config.Endpoint{{URL: "https://a"}, {URL: "https://b"}}
+	cfg.FailoverThreshold = 2
+	cfg.FailoverCooldown = 50 * time.Millisecond
+	s := newSet(t, cfg)
+
+	ctx := context.Background()
+	boom := errors.New("connection refused")
+
+	pick := func() *members.Member[string] {
+		m, err := s.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	for i := 0; i < 4; i++ {
+		m := pick()
+		var err error
+		if m.Client == "https://a" {
+			err = boom
+		}
+		s.Release(ctx, m, "/", err)
+	}
+	for i := 0; i < 8; i++ {
+		m := pick()
+		if m.Client != "https://b" {
+			t.Fatalf("request %d went to unhealthy endpoint %s", i, m.Client)
+		}
+		s.Release(ctx, m, "/", nil)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	seenA := false
+	for i := 0; i < 4; i++ {
+		m := pick()
+		seenA = seenA || m.Client == "https://a"
+		s.Release(ctx, m, "/", nil)
+	}
+	if !seenA {
+		t.Fatalf("endpoint did not come back after cooldown")
+	}
+}
+
+func TestSet_CanceledDoesNotTrip(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Size = 1
+	cfg.FailoverThreshold = 1
+	s := newSet(t, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m, _ := s.Acquire(ctx)
+	s.Release(ctx, m, "/", context.Canceled)
+	if !m.Endpoint.Healthy() {
+		t.Fatalf("caller cancellation must not mark endpoint unhealthy")
+	}
+}
+
+func TestSet_ClientErrorsDoNotTrip(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Size = 1
+	cfg.FailoverThreshold = 1
+	s := newSet(t, cfg)
+	ctx := context.Background()
+
+	for _, err := range []error{
+		&pool.BodyTooLargeError{Limit: 1},
+		&pool.Error{Kind: pool.Saturated, Member: -1, Err: context.DeadlineExceeded},
+		&pool.Error{Kind: pool.Canceled, Member: -1, Err: context.Canceled},
+	} {
+		m, _ := s.Acquire(ctx)
+		s.Release(ctx, m, "/", err)
+		if !m.Endpoint.Healthy() {
+			t.Fatalf("%v must not mark endpoint unhealthy", err)
+		}
+	}
+}
+
+type staticResolver struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (r *staticResolver) LookupHost(context.Context, string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addrs, nil
+}
+
+func (r *staticResolver) set(addrs ...string) {
+	r.mu.Lock()
+	r.addrs = addrs
+	r.mu.Unlock()
+}
+
+func TestSet_DNSRetireDrains(t *testing.T) {
+	res := &staticResolver{addrs: []string{"10.0.0.1"}}
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://svc.local"
+	cfg.Size = 2
+	cfg.DNSRefresh = 5 * time.Millisecond
+	cfg.Resolver = res
+
+	var mu sync.Mutex
+	closed := map[string]int{}
+	s, err := members.New(cfg, func(ep *members.Endpoint) string { return ep.Addr }, func(addr string) {
+		mu.Lock()
+		closed[addr]++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	busy, _ := s.Acquire(ctx)
+	if busy.Client != "10.0.0.1:443" {
+		t.Fatalf("want dial address 10.0.0.1:443, got %q", busy.Client)
+	}
+
+	res.set("10.0.0.2")
+	deadline := time.Now().Add(time.Second)
+	for {
+		ms := s.Members()
+		if len(ms) == 2 && ms[0].Client == "10.0.0.2:443" && ms[1].Client == "10.0.0.2:443" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("members were not moved to the new address")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	if closed["10.0.0.1:443"] != 1 {
+		t.Fatalf("idle retired member must be closed once, got %d", closed["10.0.0.1:443"])
+	}
+	mu.Unlock()
+
+	s.Release(ctx, busy, "/", nil)
+	mu.Lock()
+	defer mu.Unlock()
+	if closed["10.0.0.1:443"] != 2 {
+		t.Fatalf("busy retired member must be closed after release, got %d closes", closed["10.0.0.1:443"])
+	}
+}
+
+func TestSet_DNSMoreAddrsThanSize(t *testing.T) {
+	addrs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://svc.local"
+	cfg.Size = 2
+	cfg.DNSRefresh = 5 * time.Millisecond
+	cfg.Resolver = &staticResolver{addrs: addrs}
+	s, err := members.New(cfg, func(ep *members.Endpoint) string { return ep.Addr }, func(string) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Членов меньше, чем адресов: от обновления к обновлению они переходят на следующие адреса.
+	seen := map[string]bool{}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(seen) < len(addrs) {
+		if time.Now().After(deadline) {
+			t.Fatalf("members never reached some addresses, seen %v", seen)
+		}
+		ms := s.Members()
+		if len(ms) != 2 {
+			t.Fatalf("want 2 members, got %d", len(ms))
+		}
+		for _, m := range ms {
+			seen[m.Client] = true
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type chanDiscovery chan []config.Endpoint
+
+func (d chanDiscovery) Watch(context.Context) (<-chan []config.Endpoint, error) { return d, nil }
+
+func TestSet_Discovery(t *testing.T) {
+	d := make(chanDiscovery, 1)
+	d <- []config.Endpoint{{URL: "https://a"}}
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://fallback"
+	cfg.Size = 4
+	cfg.Discovery = d
+	s := newSet(t, cfg)
+	defer s.Close()
+
+	urls := func() map[string]int {
+		out := map[string]int{}
+		for _, m := range s.Members() {
+			out[m.Client]++
+		}
+		return out
+	}
+	if got := urls(); got["https://a"] != 4 {
+		t.Fatalf("want first discovery update applied before New returns, got %v", got)
+	}
+
+	d <- []config.Endpoint{{URL: "https://a"}, {URL: "https://b", Weight: 3}}
+	d <- nil // пустой набор игнорируется
+	deadline := time.Now().Add(time.Second)
+	for got := urls(); got["https://a"] != 1 || got["https://b"] != 3; got = urls() {
+		if time.Now().After(deadline) {
+			t.Fatalf("members were not reconciled with discovery update, got %v", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type failingDiscovery struct{}
+
+func (failingDiscovery) Watch(context.Context) (<-chan []config.Endpoint, error) {
+	return nil, errors.New("registry unavailable")
+}
+
+func TestSet_DiscoveryWatchError(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://a"
+	cfg.Discovery = failingDiscovery{}
+	if _, err := members.New(cfg, func(ep *members.Endpoint) string { return ep.URL }, func(string) {}); err == nil {
+		t.Fatal("want Watch error from New")
+	}
+}
+
+func TestSet_Resize(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Size = 2
+	cfg.Endpoints = []config.Endpoint{{URL: "https://a"}, {URL: "https://b"}}
+
+	var mu sync.Mutex
+	closed := 0
+	s, err := members.New(cfg, func(ep *members.Endpoint) string { return ep.URL }, func(string) {
+		mu.Lock()
+		closed++
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Resize(6); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Members()); got != 6 || s.Size() != 6 {
+		t.Fatalf("want 6 members after grow, got %d", got)
+	}
+
+	ctx := context.Background()
+	var busy []*members.Member[string]
+	for range 6 {
+		m, _ := s.Acquire(ctx)
+		busy = append(busy, m)
+	}
+	if err := s.Resize(2); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Members()); got != 2 {
+		t.Fatalf("want 2 members after shrink, got %d", got)
+	}
+	mu.Lock()
+	if closed != 0 {
+		t.Fatalf("busy members must not be closed before release, got %d closes", closed)
+	}
+	mu.Unlock()
+
+	for _, m := range busy {
+		s.Release(ctx, m, "/", nil)
+	}
+	mu.Lock()
+	if closed != 4 {
+		t.Fatalf("want 4 drained members closed, got %d", closed)
+	}
+	mu.Unlock()
+
+	if err := s.Resize(0); err == nil {
+		t.Fatal("want error for size 0")
+	}
+	s.Close()
+	if err := s.Resize(3); !errors.Is(err, pool.ErrClosed) {
+		t.Fatalf("want ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestSet_Autoscale(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://a"
+	cfg.Size = 2
+	cfg.Autoscale = config.Autoscale{
+		Enabled:           true,
+		Min:               1,
+		Max:               3,
+		Interval:          5 * time.Millisecond,
+		ScaleDownCooldown: 50 * time.Millisecond,
+	}
+	s := newSet(t, cfg)
+	defer s.Close()
+
+	waitSize := func(want int, what string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for s.Size() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: want size %d, got %d", what, want, s.Size())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Все соединения заняты — пул растёт, но не выше Max.
+	ctx := context.Background()
+	var busy []*members.Member[string]
+	for range 2 {
+		m, _ := s.Acquire(ctx)
+		busy = append(busy, m)
+	}
+	waitSize(3, "saturated pool")
+	m, _ := s.Acquire(ctx)
+	busy = append(busy, m)
+	time.Sleep(30 * time.Millisecond)
+	if s.Size() != 3 {
+		t.Fatalf("size must stay at Max=3, got %d", s.Size())
+	}
+
+	// Нагрузка ушла — пул сжимается до Min.
+	for _, m := range busy {
+		s.Release(ctx, m, "/", nil)
+	}
+	waitSize(1, "idle pool")
+}
+
+func TestSet_AutoscaleZeroFields(t *testing.T) {
+	// Конфиг, собранный в коде: Interval, Min и Max не заданы и берутся по умолчанию.
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://a"
+	cfg.Size = 2
+	cfg.Autoscale = config.Autoscale{Enabled: true}
+	s := newSet(t, cfg)
+	defer s.Close()
+
+	if s.Size() != 2 {
+		t.Fatalf("want size 2, got %d", s.Size())
+	}
+	m, err := s.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Release(context.Background(), m, "/", nil)
+
+	// Задан только Interval, Size нулевой: в пуле всё равно есть член.
+	cfg.Size = 0
+	cfg.Autoscale = config.Autoscale{Enabled: true, Interval: time.Hour}
+	s2 := newSet(t, cfg)
+	defer s2.Close()
+	if s2.Size() != 1 {
+		t.Fatalf("want size 1, got %d", s2.Size())
+	}
+}
+
+func TestSet_WarmupRedactsURL(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = "https://user:secret@a"
+	cfg.Size = 1
+	s := newSet(t, cfg)
+	defer s.Close()
+
+	rep := s.Warmup(context.Background(), 0, func(context.Context, string) error {
+		return errors.New("connection refused")
+	})
+	if len(rep.Failed) != 1 || strings.Contains(rep.Failed[0].Endpoint, "secret") {
+		t.Fatalf("warmup failure must carry a redacted URL, got %+v", rep.Failed)
+	}
+}
+
+func TestSet_SkipsBusyMember(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Size = 2
+	cfg.MaxConnsPerHost = 1
+	cfg.RequestTimeout = 50 * time.Millisecond
+	s := newSet(t, cfg)
+	ctx := context.Background()
+
+	stuck, err := s.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Round-robin не ставит запрос в очередь к занятому члену, пока другой свободен.
+	for i := 0; i < 5; i++ {
+		m, err := s.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m == stuck {
+			t.Fatalf("request %d queued behind the stuck member", i)
+		}
+		s.Release(ctx, m, "/", nil)
+	}
+
+	// Заняты оба: ожидание без дедлайна в ctx ограничено RequestTimeout.
+	other, err := s.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = s.Acquire(ctx)
+	if pool.KindOf(err) != pool.Saturated || time.Since(start) > time.Second {
+		t.Fatalf("want Saturated after RequestTimeout, got %v in %s", err, time.Since(start))
+	}
+	s.Release(ctx, other, "/", nil)
+	s.Release(ctx, stuck, "/", nil)
+}
@@ -0,0 +1,473 @@
+package members
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+	"httpclientpool/pkg/rr"
+)
+
+// Member — один клиент пула (одно соединение), привязанный к эндпоинту.
+type Member[C any] struct {
+	ID       int
+	Client   C
+	Endpoint *Endpoint
+	Stats    pool.MemberStats
+
+	// slots ограничивает число запросов, одновременно отданных клиенту: соединения
+	// (MaxConnsPerHost) для HTTP/1.1 или h2-стримы. Остальные ждут в Acquire.
+	slots     chan struct{}
+	retired   atomic.Bool
+	closeOnce sync.Once
+}
+
+// Spec — желаемый эндпоинт: базовый URL и, в DNS-режиме, конкретный адрес для dial.
+type Spec struct {
+	URL    string
+	Addr   string
+	Weight int
+}
+
+func (sp Spec) key() string { return sp.URL + "\x00" + sp.Addr }
+
+// Set — общая для бэкендов часть пула: члены, их распределение по эндпоинтам,
+// round-robin с обходом недоступных эндпоинтов, статистика и последние ошибки.
+// Состав членов можно менять на лету: выбывшие члены больше не выбираются и
+// закрываются, когда на них заканчиваются запросы в полёте.
+// C — клиент конкретного бэкенда.
+type Set[C any] struct {
+	cfg         config.Config
+	newClient   func(ep *Endpoint) C
+	closeClient func(C)
+
+	members  atomic.Pointer[[]*Member[C]]
+	spin     rr.RR
+	errs     pool.ErrorLog
+	capacity int
+	load     load
+
+	mu        sync.Mutex
+	base      []Spec // эндпоинты из конфига или от Discovery, до DNS-резолва
+	endpoints []*Endpoint
+	specs     []Spec
+	size      int
+	nextID    int
+	// offset — с какого эндпоинта Spread раздаёт членов при ничьей; в DNS-режиме
+	// сдвигается на каждом обновлении, если адресов больше, чем членов (см. watchDNS).
+	offset int
+	closed bool
+
+	// closing выставляется в Shutdown/Close: новые запросы получают pool.ErrClosed.
+	closing atomic.Bool
+
+	life context.Context // отменяется в Close
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func New[C any](cfg config.Config, newClient func(ep *Endpoint) C, closeClient func(C)) (*Set[C], error) {
+	if cfg.Autoscale.Enabled {
+		cfg.Autoscale = autoscaleDefaults(cfg.Autoscale)
+	}
+	s := &Set[C]{cfg: cfg, newClient: newClient, closeClient: closeClient, capacity: capacity(cfg)}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.life, s.stop = ctx, cancel
+
+	base := toSpecs(cfg.EndpointList())
+	var updates <-chan []config.Endpoint
+	if cfg.Discovery != nil {
+		ch, err := cfg.Discovery.Watch(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		updates = ch
+		base = s.firstDiscovery(ctx, updates, base)
+	}
+
+	specs := base
+	if cfg.DNSRefresh > 0 {
+		specs = s.initialDNS(ctx, base)
+	}
+
+	size := cfg.Size
+	if a := cfg.Autoscale; a.Enabled {
+		size = min(max(size, a.Min), a.Max)
+	}
+	size = max(size, 1)
+	s.mu.Lock()
+	s.base = base
+	s.apply(specs, size)
+	s.mu.Unlock()
+
+	if updates != nil {
+		s.wg.Add(1)
+		go s.watchDiscovery(ctx, updates)
+	}
+	if cfg.DNSRefresh > 0 {
+		s.wg.Add(1)
+		go s.watchDNS(ctx)
+	}
+	if cfg.Autoscale.Enabled {
+		s.wg.Add(1)
+		go s.autoscale(ctx)
+	}
+	return s, nil
+}
+
+// capacity — сколько запросов член пула обслуживает одновременно.
+func capacity(cfg config.Config) int {
+	if cfg.Protocol == config.ProtocolHTTP2 || cfg.Protocol == config.ProtocolH2C {
+		// Свой лимит, чтобы net/http не открывал второе соединение, упёршись в лимит сервера.
+		if cfg.MaxConcurrentStreams > 0 {
+			return cfg.MaxConcurrentStreams
+		}
+		return config.DefaultMaxConcurrentStreams
+	}
+	return max(cfg.MaxConnsPerHost, 1)
+}
+
+func toSpecs(eps []config.Endpoint) []Spec {
+	specs := make([]Spec, len(eps))
+	for i, e := range eps {
+		w := e.Weight
+		if w <= 0 {
+			w = 1
+		}
+		specs[i] = Spec{URL: e.URL, Weight: w}
+	}
+	return specs
+}
+
+// apply приводит состав членов к specs и size. Вызывается под s.mu.
+// Члены эндпоинтов, которые остались в specs, переиспользуются вместе с их соединениями.
+func (s *Set[C]) apply(specs []Spec, size int) {
+	specs = dedupe(specs)
+	weights := make([]int, len(specs))
+	for i, sp := range specs {
+		weights[i] = sp.Weight
+	}
+	counts := spreadFrom(weights, size, s.offset)
+	if s.cfg.DNSRefresh > 0 && len(specs) > size && !slices.Equal(specs, s.specs) {
+		s.cfg.Log().Warn("httpclientpool: more addresses than pool members, members rotate across them on each DNS refresh",
+			"pool", s.cfg.Name, "addrs", len(specs), "size", size)
+	}
+
+	oldEps := make(map[string]*Endpoint, len(s.endpoints))
+	for _, e := range s.endpoints {
+		oldEps[e.key()] = e
+	}
+	byEp := make(map[*Endpoint][]*Member[C])
+	if cur := s.members.Load(); cur != nil {
+		for _, m := range *cur {
+			byEp[m.Endpoint] = append(byEp[m.Endpoint], m)
+		}
+	}
+
+	var retire []*Member[C]
+	eps := make([]*Endpoint, 0, len(specs))
+	groups := make([][]*Member[C], len(specs))
+	for i, sp := range specs {
+		ep, ok := oldEps[sp.key()]
+		if !ok {
+			ep = &Endpoint{
+				URL:       sp.URL,
+				Addr:      sp.Addr,
+				threshold: int64(s.cfg.FailoverThreshold),
+				cooldown:  s.cfg.FailoverCooldown,
+			}
+		}
+		ep.Weight = sp.Weight
+		eps = append(eps, ep)
+
+		keep := byEp[ep]
+		delete(byEp, ep)
+		if len(keep) > counts[i] {
+			retire = append(retire, keep[counts[i]:]...)
+			keep = keep[:counts[i]]
+		}
+		for len(keep) < counts[i] {
+			keep = append(keep, s.newMember(ep))
+		}
+		groups[i] = keep
+	}
+	for _, gone := range byEp {
+		retire = append(retire, gone...)
+	}
+
+	// Чередуем эндпоинты, чтобы соседние по round-robin члены смотрели в разные места.
+	ms := make([]*Member[C], 0, size)
+	for round := 0; len(ms) < size; round++ {
+		for _, g := range groups {
+			if round < len(g) {
+				ms = append(ms, g[round])
+			}
+		}
+	}
+
+	s.members.Store(&ms)
+	s.endpoints, s.specs, s.size = eps, specs, size
+	for _, m := range retire {
+		s.retire(m)
+	}
+}
+
+func dedupe(specs []Spec) []Spec {
+	seen := make(map[string]bool, len(specs))
+	out := specs[:0:0]
+	for _, sp := range specs {
+		if !seen[sp.key()] {
+			seen[sp.key()] = true
+			out = append(out, sp)
+		}
+	}
+	return out
+}
+
+// newMember вызывается под s.mu.
+func (s *Set[C]) newMember(ep *Endpoint) *Member[C] {
+	id := s.nextID
+	s.nextID++
+	return &Member[C]{ID: id, Client: s.newClient(ep), Endpoint: ep, slots: make(chan struct{}, s.capacity)}
+}
+
+func (s *Set[C]) retire(m *Member[C]) {
+	m.retired.Store(true)
+	s.closeIfDrained(m)
+}
+
+func (s *Set[C]) closeIfDrained(m *Member[C]) {
+	if m.retired.Load() && m.Stats.InFlight() == 0 {
+		m.closeOnce.Do(func() { s.closeClient(m.Client) })
+	}
+}
+
+func (s *Set[C]) Members() []*Member[C] { return *s.members.Load() }
+
+// Size — текущее число членов.
+func (s *Set[C]) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Resize меняет число членов на лету. Лишние члены выбывают и закрываются, доработав
+// запросы в полёте; новые распределяются по эндпоинтам по весам. Безопасен при
+// параллельных Acquire/Release и обновлениях из DNS/Discovery.
+func (s *Set[C]) Resize(n int) error {
+	if n < 1 {
+		return fmt.Errorf("httpclientpool: size must be >= 1, got %d", n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return pool.ErrClosed
+	}
+	if n != s.size {
+		s.apply(s.specs, n)
+	}
+	return nil
+}
+
+// Acquire выбирает следующего члена по round-robin, пропуская тех, чей эндпоинт
+// сейчас недоступен, и тех, у кого заняты все соединения. Если недоступны все — берёт
+// очередного как есть. Если заняты все, Acquire ждёт свободное соединение у выбранного
+// члена до отмены ctx, но не дольше RequestTimeout.
+func (s *Set[C]) Acquire(ctx context.Context) (*Member[C], error) {
+	m, err := s.pick()
+	if err != nil {
+		return nil, err
+	}
+	return s.wait(ctx, m)
+}
+
+// errNoFreeSlot — за RequestTimeout у члена так и не освободилось соединение.
+var errNoFreeSlot = errors.New("httpclientpool: no free connection within request timeout")
+
+// wait занимает слот члена, для которого уже сделан Begin.
+func (s *Set[C]) wait(ctx context.Context, m *Member[C]) (*Member[C], error) {
+	s.load.begin()
+	select {
+	case m.slots <- struct{}{}:
+		s.load.waited(0)
+		return m, nil
+	default:
+	}
+	// Без ctx (fiber Get/Post) ожидание ограничено так же, как MaxConnWaitTimeout fasthttp.
+	var expired <-chan time.Time
+	if d := s.cfg.RequestTimeout; d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		expired = t.C
+	}
+	start := time.Now()
+	var err *pool.Error
+	select {
+	case m.slots <- struct{}{}:
+		s.load.waited(time.Since(start))
+		return m, nil
+	case <-expired:
+		err = &pool.Error{Kind: pool.Saturated, Member: m.ID, Err: errNoFreeSlot}
+	case <-ctx.Done():
+		// Дедлайн, истёкший в очереди за слотом, — признак нехватки соединений, а не отмена.
+		kind := pool.Canceled
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			kind = pool.Saturated
+		}
+		err = &pool.Error{Kind: kind, Member: m.ID, Err: ctx.Err()}
+	}
+	s.load.end()
+	m.Stats.Abort()
+	s.closeIfDrained(m)
+	return nil, err
+}
+
+// errPoolClosed — ответ Acquire закрытому пулу; errors.Is видит и pool.PoolClosed, и pool.ErrClosed.
+func errPoolClosed() error {
+	return &pool.Error{Kind: pool.PoolClosed, Member: -1, Err: pool.ErrClosed}
+}
+
+func (s *Set[C]) pick() (*Member[C], error) {
+	for {
+		if s.closing.Load() {
+			return nil, errPoolClosed()
+		}
+		ms := s.Members()
+		start := s.spin.Next(len(ms))
+		// Первый здоровый член со свободным соединением, иначе первый здоровый, иначе очередной.
+		m, healthy := ms[start], (*Member[C])(nil)
+		for k := 0; k < len(ms); k++ {
+			c := ms[(start+k)%len(ms)]
+			if !c.Endpoint.Healthy() {
+				continue
+			}
+			if len(c.slots) < cap(c.slots) {
+				healthy = c
+				break
+			}
+			if healthy == nil {
+				healthy = c
+			}
+		}
+		if healthy != nil {
+			m = healthy
+		}
+		m.Stats.Begin()
+		// closing перепроверяется после Begin: Shutdown, увидевший ноль запросов в полёте,
+		// не должен закрыть член под запросом, который проскочил первую проверку.
+		if s.closing.Load() {
+			m.Stats.Abort()
+			return nil, errPoolClosed()
+		}
+		if !m.retired.Load() {
+			return m, nil
+		}
+		// Член выбыл между загрузкой списка и Begin — берём из свежего списка.
+		m.Stats.Abort()
+		s.closeIfDrained(m)
+	}
+}
+
+// Release фиксирует результат запроса и возвращает его ошибку, приведённую к *pool.Error.
+// Ошибки на стороне клиента (отмена ctx вызывающим, предел тела ответа, нехватка
+// соединений в самом пуле) не считаются отказом эндпоинта.
+func (s *Set[C]) Release(ctx context.Context, m *Member[C], path string, err error) error {
+	err = pool.NewError(ctx, m.ID, err)
+	<-m.slots
+	s.load.end()
+	m.Stats.End(err)
+	if err != nil {
+		s.errs.Add(m.ID, path, err)
+	}
+	if err == nil || ctx.Err() == nil && !clientSide(err) {
+		m.Endpoint.observe(err)
+	}
+	s.closeIfDrained(m)
+	return err
+}
+
+// clientSide — ошибки, которые ничего не говорят о здоровье эндпоинта.
+func clientSide(err error) bool {
+	switch pool.KindOf(err) {
+	case pool.Canceled, pool.BodyTooLarge, pool.Saturated:
+		return true
+	}
+	return false
+}
+
+func (s *Set[C]) Snapshot(backend string, cfg config.Config) pool.Snapshot {
+	ms := s.Members()
+	perEndpoint := make(map[*Endpoint]int)
+	members := make([]pool.MemberSnapshot, 0, len(ms))
+	for _, m := range ms {
+		perEndpoint[m.Endpoint]++
+		members = append(members, m.Stats.Snapshot(m.ID, config.RedactURL(m.Endpoint.URL), m.Endpoint.Addr))
+	}
+
+	s.mu.Lock()
+	eps := make([]pool.EndpointSnapshot, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		eps = append(eps, e.snapshot(perEndpoint[e]))
+	}
+	cfg.Size = s.size
+	s.mu.Unlock()
+
+	return pool.Snapshot{
+		Name:         cfg.Name,
+		Backend:      backend,
+		Config:       cfg.Redacted(),
+		Endpoints:    eps,
+		Members:      members,
+		RecentErrors: s.errs.Recent(),
+	}
+}
+
+// Shutdown перестаёт принимать запросы (pool.ErrClosed), ждёт завершения запросов
+// в полёте на всех членах и закрывает соединения. Если ctx истёк раньше, соединения
+// закрываются сразу, а Shutdown возвращает ошибку ctx.
+func (s *Set[C]) Shutdown(ctx context.Context) error {
+	s.closing.Store(true)
+	defer s.Close()
+
+	// Опрос с растущим интервалом, как в http.Server.Shutdown.
+	interval := time.Millisecond
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for s.inFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+		interval = min(interval*2, 100*time.Millisecond)
+		t.Reset(interval)
+	}
+	return nil
+}
+
+func (s *Set[C]) inFlight() int64 {
+	var n int64
+	for _, m := range s.Members() {
+		n += m.Stats.InFlight()
+	}
+	return n
+}
+
+func (s *Set[C]) Close() {
+	s.closing.Store(true)
+	s.stop()
+	s.wg.Wait()
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	for _, m := range s.Members() {
+		m.closeOnce.Do(func() { s.closeClient(m.Client) })
+	}
+}
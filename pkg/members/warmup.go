@@ -0,0 +1,101 @@
+package members
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+type warmResult struct {
+	f   pool.WarmupFailure
+	err error
+}
+
+// Warmup параллельно прогревает всех текущих членов функцией warm и возвращается,
+// когда прогрелись enough членов или закончились все попытки. enough <= 0 — ждать всех.
+// Недождавшиеся прогревы продолжаются, пока не закончится ctx.
+func (s *Set[C]) Warmup(ctx context.Context, enough int, warm func(ctx context.Context, c C) error) pool.WarmupReport {
+	rep, _ := s.warmup(ctx, enough, warm)
+	return rep
+}
+
+// warmup — Warmup, который ещё и возвращает канал, закрывающийся, когда закончились
+// все прогревы, включая недождавшиеся.
+func (s *Set[C]) warmup(ctx context.Context, enough int, warm func(ctx context.Context, c C) error) (pool.WarmupReport, <-chan struct{}) {
+	ms := s.Members()
+	if enough <= 0 || enough > len(ms) {
+		enough = len(ms)
+	}
+
+	results := make(chan warmResult, len(ms))
+	var wg sync.WaitGroup
+	for _, m := range ms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Stats.Begin()
+			res := warmResult{f: pool.WarmupFailure{Member: m.ID, Endpoint: config.RedactURL(m.Endpoint.URL), Addr: m.Endpoint.Addr}}
+			if _, err := s.wait(ctx, m); err != nil {
+				res.err = err
+				results <- res
+				return
+			}
+			res.err = s.Release(ctx, m, "warmup", warm(ctx, m.Client))
+			results <- res
+		}()
+	}
+
+	var rep pool.WarmupReport
+	for done := 0; done < len(ms) && rep.Ready < enough; done++ {
+		r := <-results
+		if r.err != nil {
+			r.f.Err = r.err
+			rep.Failed = append(rep.Failed, r.f)
+			continue
+		}
+		rep.Ready++
+	}
+	rep.Pending = len(ms) - rep.Ready - len(rep.Failed)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return rep, done
+}
+
+// WarmupOnNew — прогрев при создании пула по cfg.Warmup. Возвращает ошибку, если за
+// Timeout прогрелось меньше MinReady членов (0 — все).
+func (s *Set[C]) WarmupOnNew(warm func(ctx context.Context, c C) error) error {
+	w := s.cfg.Warmup
+	if !w.Enabled {
+		return nil
+	}
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = s.cfg.RequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(s.life, timeout)
+	rep, done := s.warmup(ctx, w.MinReady, warm)
+	// Не отменяем при раннем возврате: остальные члены догреваются в фоне до таймаута.
+	go func() {
+		<-done
+		cancel()
+	}()
+	need := w.MinReady
+	if need <= 0 || need > len(s.Members()) {
+		need = len(s.Members())
+	}
+	if rep.Ready < need {
+		return fmt.Errorf("httpclientpool: warmup: %d of %d members ready, need %d: %w",
+			rep.Ready, rep.Ready+len(rep.Failed)+rep.Pending, need, rep.Err())
+	}
+	if len(rep.Failed) > 0 {
+		s.cfg.Log().Warn("httpclientpool: some members failed to warm up", "pool", s.cfg.Name,
+			"ready", rep.Ready, "failed", len(rep.Failed), "err", rep.Err())
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -41,9 +42,8 @@ func newH1TLSServer(latency time.Duration, payload any) *httptest.Server {
 }
 
 func cfgFor(url string) config.Config {
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = url
-	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
 	cfg.MaxConnsPerHost = 1
 	cfg.RequestTimeout = 5 * time.Second
@@ -52,9 +52,12 @@ func cfgFor(url string) config.Config {
 	return cfg
 }
 
-func benchClient(b *testing.B, name string, mk func() pool.Client, path string, par int) {
+func benchClient(b *testing.B, name string, mk func() (pool.Client, error), path string, par int) {
 	b.Helper()
-	cl := mk()
+	cl, err := mk()
+	if err != nil {
+		b.Fatalf("%s new: %v", name, err)
+	}
 	defer cl.Close()
 
 	ctx := context.Background()
@@ -79,6 +82,7 @@ func benchClient(b *testing.B, name string, mk func() pool.Client, path string,
 				}())
 			}
 			_ = len(resp.Body())
+			resp.Release()
 		}
 	})
 }
@@ -91,13 +95,13 @@ func BenchmarkPools_Small(b *testing.B) {
 	par := cfg.Size
 
 	b.Run("resty/small", func(b *testing.B) {
-		benchClient(b, "resty", func() pool.Client {
+		benchClient(b, "resty", func() (pool.Client, error) {
 			return restypool.New(cfg)
 		}, "/ping", par)
 	})
 
 	b.Run("fiber/small", func(b *testing.B) {
-		benchClient(b, "fiber", func() pool.Client {
+		benchClient(b, "fiber", func() (pool.Client, error) {
 			return fiberpool.New(cfg)
 		}, "/ping", par)
 	})
@@ -111,13 +115,83 @@ func BenchmarkPools_Large(b *testing.B) {
 	par := cfg.Size
 
 	b.Run("resty/large", func(b *testing.B) {
-		benchClient(b, "resty", func() pool.Client {
+		benchClient(b, "resty", func() (pool.Client, error) {
 			return restypool.New(cfg)
 		}, "/large", par)
 	})
 
 	b.Run("fiber/large", func(b *testing.B) {
-		benchClient(b, "fiber", func() pool.Client {
+		benchClient(b, "fiber", func() (pool.Client, error) {
+			return fiberpool.New(cfg)
+		}, "/large", par)
+	})
+}
+
+// BenchmarkPools_LargePooled — /large с PooledBuffers: тело не копируется в новый срез на каждый запрос.
+func BenchmarkPools_LargePooled(b *testing.B) {
+	srv := newH1TLSServer(2*time.Millisecond, map[string]any{"ok": true})
+	defer srv.Close()
+
+	cfg := cfgFor(srv.URL)
+	cfg.PooledBuffers = true
+	par := cfg.Size
+
+	b.Run("resty/large-pooled", func(b *testing.B) {
+		benchClient(b, "resty", func() (pool.Client, error) {
+			return restypool.New(cfg)
+		}, "/large", par)
+	})
+
+	b.Run("fiber/large-pooled", func(b *testing.B) {
+		benchClient(b, "fiber", func() (pool.Client, error) {
+			return fiberpool.New(cfg)
+		}, "/large", par)
+	})
+}
+
+// benchStream — то же, что benchClient, но тело читается через Stream без буферизации.
+func benchStream(b *testing.B, name string, mk func() (pool.Client, error), path string, par int) {
+	b.Helper()
+	cl, err := mk()
+	if err != nil {
+		b.Fatalf("%s new: %v", name, err)
+	}
+	defer cl.Close()
+
+	ctx := context.Background()
+	req := pool.Request{Path: path}
+
+	b.ReportAllocs()
+	b.SetParallelism(par)
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			resp, err := cl.Stream(ctx, req)
+			if err != nil || resp.StatusCode != 200 {
+				b.Fatalf("%s STREAM %s err=%v", name, path, err)
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	})
+}
+
+func BenchmarkPools_LargeStream(b *testing.B) {
+	srv := newH1TLSServer(2*time.Millisecond, map[string]any{"ok": true})
+	defer srv.Close()
+
+	cfg := cfgFor(srv.URL)
+	par := cfg.Size
+
+	b.Run("resty/large-stream", func(b *testing.B) {
+		benchStream(b, "resty", func() (pool.Client, error) {
+			return restypool.New(cfg)
+		}, "/large", par)
+	})
+
+	b.Run("fiber/large-stream", func(b *testing.B) {
+		benchStream(b, "fiber", func() (pool.Client, error) {
 			return fiberpool.New(cfg)
 		}, "/large", par)
 	})
@@ -0,0 +1,320 @@
+package pool_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/httpcache"
+	"httpclientpool/pkg/pool"
+)
+
+func testCache(t *testing.T, newClient ClientFactory) {
+	var hits, notModified atomic.Int64
+	lastMod := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+			fmt.Fprintf(w, "fresh %d", n)
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+			fmt.Fprintf(w, "no-store %d", n)
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprintf(w, "etag %d", n)
+		case "/last-modified":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("Last-Modified", lastMod)
+			if r.Header.Get("If-Modified-Since") == lastMod {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprintf(w, "last-modified %d", n)
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "X-Lang")
+			fmt.Fprintf(w, "%s %d", r.Header.Get("X-Lang"), n)
+		case "/swr":
+			w.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=30")
+			fmt.Fprintf(w, "swr %d", n)
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+	c := httpcache.New(mustNew(t, newClient, cfg), httpcache.Options{})
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	get := func(t *testing.T, req pool.Request) string {
+		t.Helper()
+		resp, err := c.Do(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Release()
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("%s: status %d", req.Path, resp.StatusCode())
+		}
+		return string(resp.Body())
+	}
+	upstream := func(t *testing.T, f func()) int64 {
+		t.Helper()
+		before := hits.Load()
+		f()
+		return hits.Load() - before
+	}
+
+	t.Run("MaxAge", func(t *testing.T) {
+		var first, second string
+		n := upstream(t, func() {
+			first = get(t, pool.Request{Path: "/fresh"})
+			resp, err := c.Get(ctx, "/fresh")
+			if err != nil {
+				t.Fatal(err)
+			}
+			second = string(resp.Body())
+			if resp.Header().Get("Age") == "" {
+				t.Fatal("cached response must carry Age")
+			}
+			// У каждого вызывающего своя копия тела.
+			resp.Body()[0] = 'X'
+		})
+		if n != 1 || first != second || get(t, pool.Request{Path: "/fresh"}) != first {
+			t.Fatalf("want one upstream call and equal bodies, got %d calls, %q and %q", n, first, second)
+		}
+
+		// Cache-Control: no-cache в запросе — мимо свежей копии.
+		n = upstream(t, func() {
+			get(t, pool.Request{Path: "/fresh", Header: http.Header{"Cache-Control": {"no-cache"}}})
+		})
+		if n != 1 {
+			t.Fatalf("request no-cache must reach upstream, got %d calls", n)
+		}
+
+		// Успешный POST удаляет сохранённый ответ.
+		if _, err := c.Post(ctx, "/fresh", map[string]int{"a": 1}); err != nil {
+			t.Fatal(err)
+		}
+		if n := upstream(t, func() { get(t, pool.Request{Path: "/fresh"}) }); n != 1 {
+			t.Fatalf("POST must invalidate, got %d calls", n)
+		}
+	})
+
+	t.Run("NoStore", func(t *testing.T) {
+		n := upstream(t, func() {
+			get(t, pool.Request{Path: "/no-store"})
+			get(t, pool.Request{Path: "/no-store"})
+		})
+		if n != 2 {
+			t.Fatalf("no-store must not be cached, got %d calls", n)
+		}
+	})
+
+	t.Run("ETag", func(t *testing.T) {
+		first := get(t, pool.Request{Path: "/etag"})
+		before := notModified.Load()
+		n := upstream(t, func() {
+			if got := get(t, pool.Request{Path: "/etag"}); got != first {
+				t.Fatalf("revalidated body: got %q, want %q", got, first)
+			}
+		})
+		if n != 1 || notModified.Load()-before != 1 {
+			t.Fatalf("want one conditional request answered 304, got %d calls", n)
+		}
+	})
+
+	t.Run("LastModified", func(t *testing.T) {
+		first := get(t, pool.Request{Path: "/last-modified"})
+		before := notModified.Load()
+		if got := get(t, pool.Request{Path: "/last-modified"}); got != first || notModified.Load()-before != 1 {
+			t.Fatalf("want 304 revalidation by If-Modified-Since, got %q", got)
+		}
+	})
+
+	t.Run("Vary", func(t *testing.T) {
+		en := http.Header{"X-Lang": {"en"}}
+		de := http.Header{"X-Lang": {"de"}}
+		n := upstream(t, func() {
+			a := get(t, pool.Request{Path: "/vary", Header: en})
+			if b := get(t, pool.Request{Path: "/vary", Header: en}); b != a {
+				t.Fatalf("same X-Lang: got %q, want %q", b, a)
+			}
+			if b := get(t, pool.Request{Path: "/vary", Header: de}); b[:2] != "de" {
+				t.Fatalf("other X-Lang must not get the cached variant, got %q", b)
+			}
+		})
+		if n != 2 {
+			t.Fatalf("want 2 upstream calls, got %d", n)
+		}
+
+		// Оба варианта хранятся рядом: чередование не вытесняет их друг другом.
+		n = upstream(t, func() {
+			for range 2 {
+				for _, h := range []http.Header{{"X-Lang": {"fr"}}, {"X-Lang": {"it"}}} {
+					if b := get(t, pool.Request{Path: "/vary", Header: h}); b[:2] != h.Get("X-Lang") {
+						t.Fatalf("want %s variant, got %q", h.Get("X-Lang"), b)
+					}
+				}
+			}
+			if b := get(t, pool.Request{Path: "/vary", Header: en}); b[:2] != "en" {
+				t.Fatalf("want en variant, got %q", b)
+			}
+		})
+		if n != 2 {
+			t.Fatalf("alternating variants must hit on the second round, got %d upstream calls", n)
+		}
+	})
+
+	t.Run("StaleWhileRevalidate", func(t *testing.T) {
+		first := get(t, pool.Request{Path: "/swr"})
+		time.Sleep(1100 * time.Millisecond)
+
+		// Устаревший ответ отдаётся сразу, перепроверка идёт в фоне.
+		if got := get(t, pool.Request{Path: "/swr"}); got != first {
+			t.Fatalf("want stale %q, got %q", first, got)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got := get(t, pool.Request{Path: "/swr"})
+			if got != first {
+				if _, err := strconv.Atoi(got[len("swr "):]); err != nil {
+					t.Fatalf("unexpected body %q", got)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("background revalidation did not refresh the entry")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+
+	t.Run("InvalidateDuringFill", func(t *testing.T) {
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+		store := &pausingStore{Store: httpcache.NewLRU(0)}
+		cc := httpcache.New(keepOpen{p}, httpcache.Options{Store: store})
+		en := http.Header{"X-Lang": {"en"}}
+		if _, err := cc.Do(ctx, pool.Request{Path: "/vary", Header: en}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Вариант de сохраняется поверх en; POST приходит, пока сохранение не закончено.
+		store.pause(2)
+		filled := make(chan error, 1)
+		go func() {
+			_, err := cc.Do(ctx, pool.Request{Path: "/vary", Header: http.Header{"X-Lang": {"de"}}})
+			filled <- err
+		}()
+		<-store.paused
+		posted := make(chan error, 1)
+		go func() {
+			_, err := cc.Post(ctx, "/vary", nil)
+			posted <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		close(store.resume)
+		if err := <-filled; err != nil {
+			t.Fatal(err)
+		}
+		if err := <-posted; err != nil {
+			t.Fatal(err)
+		}
+
+		n := upstream(t, func() {
+			resp, err := cc.Do(ctx, pool.Request{Path: "/vary", Header: en})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Release()
+		})
+		if n != 1 {
+			t.Fatal("invalidated variant came back into the cache")
+		}
+	})
+
+	t.Run("CloseStopsRevalidation", func(t *testing.T) {
+		// Пул под кэшем остаётся открытым: иначе перепроверка не дошла бы до сервера и так.
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+		cc := httpcache.New(keepOpen{p}, httpcache.Options{})
+		resp, err := cc.Get(ctx, "/swr")
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := string(resp.Body())
+		time.Sleep(1100 * time.Millisecond)
+		cc.Close()
+
+		// После Close устаревший ответ ещё отдаётся, но фоновая перепроверка не начинается.
+		n := upstream(t, func() {
+			resp, err := cc.Get(ctx, "/swr")
+			if err != nil || string(resp.Body()) != first {
+				t.Fatalf("want stale %q after Close, got %v", first, err)
+			}
+			time.Sleep(100 * time.Millisecond)
+		})
+		if n != 0 {
+			t.Fatalf("revalidation must not start after Close, got %d upstream calls", n)
+		}
+	})
+}
+
+// keepOpen — pool.Client, который Close не закрывает.
+type keepOpen struct{ pool.Client }
+
+func (keepOpen) Close() {}
+
+// pausingStore задерживает результат n-го после pause вызова Get до закрытия resume.
+type pausingStore struct {
+	httpcache.Store
+	mu     sync.Mutex
+	n      int
+	paused chan struct{}
+	resume chan struct{}
+}
+
+func (s *pausingStore) pause(n int) {
+	s.mu.Lock()
+	s.n = n
+	s.paused, s.resume = make(chan struct{}), make(chan struct{})
+	s.mu.Unlock()
+}
+
+func (s *pausingStore) Get(key string) (*httpcache.Entry, bool) {
+	s.mu.Lock()
+	s.n--
+	stop := s.n == 0
+	s.mu.Unlock()
+	e, ok := s.Store.Get(key)
+	if stop {
+		close(s.paused)
+		<-s.resume
+	}
+	return e, ok
+}
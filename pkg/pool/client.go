@@ -1,14 +1,45 @@
 package pool
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"httpclientpool/pkg/config"
+)
+
+// ErrClosed возвращается операциями над уже закрытым пулом.
+var ErrClosed = errors.New("httpclientpool: pool is closed")
 
 type Client interface {
 	Get(ctx context.Context, path string) (Response, error)
 	Post(ctx context.Context, path string, body any) (Response, error)
+	// Do выполняет произвольный запрос; тело запроса (Request.Body) отправляется потоком.
+	Do(ctx context.Context, req Request) (Response, error)
+	// Stream отдаёт тело ответа потоком, не буферизуя его целиком (см. StreamResponse).
+	Stream(ctx context.Context, req Request) (*StreamResponse, error)
 	Close()
 }
 
+// Shutdowner — пул с мягкой остановкой: новые запросы получают ErrClosed,
+// запросы в полёте дорабатывают, потом соединения закрываются.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Resizer — пул, число соединений которого можно менять на лету.
+type Resizer interface {
+	Resize(n int) error
+}
+
 type Response interface {
 	StatusCode() int
 	Body() []byte
+	Header() http.Header
+	// Release возвращает буфер тела в пул (config.PooledBuffers); после него Body и Header
+	// использовать нельзя. Без PooledBuffers ничего не делает. Повторный вызов безопасен.
+	Release()
 }
+
+// Discovery — источник наборов эндпоинтов для пула, см. config.Config.Discovery и пакет discovery.
+type Discovery = config.Discovery
@@ -3,9 +3,9 @@ package pool_test
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -20,43 +20,88 @@ import (
 	"httpclientpool/pkg/restypool"
 )
 
-func newH1TLSServerWithHandler(h http.Handler) *httptest.Server {
+// newTLSServerWithHandler поднимает TLS-сервер, который умеет и HTTP/1.1, и h2:
+// протокол выбирает клиент через ALPN.
+func newTLSServerWithHandler(h http.Handler) *httptest.Server {
 	srv := httptest.NewUnstartedServer(h)
-	srv.Config.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	srv.EnableHTTP2 = true
 	srv.StartTLS()
 	return srv
 }
 
-type ClientFactory func(cfg config.Config) pool.Client
+type ClientFactory func(cfg config.Config) (pool.Client, error)
 
 type SuiteOpts struct {
 	HasResponseHeaderTimeout bool
 	SupportsContext          bool
 	ParallelWorkers          int
+	Protocol                 string
+	WantProto                string
 }
 
 func Test_ClientPools(t *testing.T) {
-	RunClientSuite(t, "resty", func(cfg config.Config) pool.Client {
+	RunClientSuite(t, "resty", func(cfg config.Config) (pool.Client, error) {
 		return restypool.New(cfg)
 	}, SuiteOpts{
 		HasResponseHeaderTimeout: true,
 		SupportsContext:          true,
 		ParallelWorkers:          200,
+		WantProto:                "HTTP/1.1",
 	})
 
-	RunClientSuite(t, "fiber", func(cfg config.Config) pool.Client {
+	RunClientSuite(t, "resty-h2", func(cfg config.Config) (pool.Client, error) {
+		return restypool.New(cfg)
+	}, SuiteOpts{
+		HasResponseHeaderTimeout: true,
+		SupportsContext:          true,
+		ParallelWorkers:          200,
+		Protocol:                 config.ProtocolHTTP2,
+		WantProto:                "HTTP/2.0",
+	})
+
+	RunClientSuite(t, "fiber", func(cfg config.Config) (pool.Client, error) {
 		return fiberpool.New(cfg)
 	}, SuiteOpts{
 		HasResponseHeaderTimeout: false, // fasthttp/fiber client это не экспонирует
 		SupportsContext:          false, // у fiber нет per-request ctx API
 		ParallelWorkers:          64,    // чуть мягче стресс для fasthttp
+		WantProto:                "HTTP/1.1",
 	})
 }
 
+func mustNew(t *testing.T, newClient ClientFactory, cfg config.Config) pool.Client {
+	t.Helper()
+	p, err := newClient(cfg)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return p
+}
+
 func RunClientSuite(t *testing.T, name string, newClient ClientFactory, opts SuiteOpts) {
 	t.Helper()
 
+	if opts.Protocol != "" {
+		base := newClient
+		newClient = func(cfg config.Config) (pool.Client, error) {
+			cfg.Protocol = opts.Protocol
+			return base(cfg)
+		}
+	}
+
+	t.Run(name+"/Protocol", func(t *testing.T) { testProtocol(t, newClient, opts.WantProto) })
+
 	t.Run(name+"/GetPost", func(t *testing.T) { testGetPost(t, newClient) })
+	t.Run(name+"/JSON", func(t *testing.T) { testJSON(t, newClient) })
+	t.Run(name+"/Errors", func(t *testing.T) { testErrors(t, newClient) })
+	t.Run(name+"/Stream", func(t *testing.T) { testStream(t, newClient) })
+	t.Run(name+"/Upload", func(t *testing.T) { testUpload(t, newClient) })
+	t.Run(name+"/PooledBuffers", func(t *testing.T) { testPooledBuffers(t, newClient) })
+	t.Run(name+"/Compression", func(t *testing.T) { testCompression(t, newClient) })
+	t.Run(name+"/BodyLimits", func(t *testing.T) { testBodyLimits(t, newClient) })
+	t.Run(name+"/Cache", func(t *testing.T) { testCache(t, newClient) })
+	t.Run(name+"/Coalesce", func(t *testing.T) { testCoalesce(t, newClient) })
+	t.Run(name+"/Idempotency", func(t *testing.T) { testIdempotency(t, newClient) })
 
 	if opts.SupportsContext {
 		t.Run(name+"/ContextTimeout", func(t *testing.T) { testContextTimeout(t, newClient) })
@@ -79,6 +124,22 @@ func RunClientSuite(t *testing.T, name string, newClient ClientFactory, opts Sui
 	})
 
 	t.Run(name+"/CloseIdempotent", func(t *testing.T) { testCloseIdempotent(t, newClient) })
+	t.Run(name+"/VerifiesTLSByDefault", func(t *testing.T) { testVerifiesTLSByDefault(t, newClient) })
+	t.Run(name+"/InsecureWarning", func(t *testing.T) { testInsecureWarning(t, newClient) })
+	t.Run(name+"/MutualTLS", func(t *testing.T) { testMutualTLS(t, newClient) })
+	t.Run(name+"/ClientCertReload", func(t *testing.T) { testClientCertReload(t, newClient) })
+	t.Run(name+"/Pinning", func(t *testing.T) { testPinning(t, newClient) })
+	t.Run(name+"/MultiEndpoint", func(t *testing.T) { testMultiEndpoint(t, newClient) })
+	t.Run(name+"/DNS", func(t *testing.T) { testDNS(t, newClient) })
+	t.Run(name+"/Proxy", func(t *testing.T) { testProxy(t, newClient) })
+	t.Run(name+"/SocksProxy", func(t *testing.T) { testSocksProxy(t, newClient) })
+	t.Run(name+"/UnixSocket", func(t *testing.T) { testUnixSocket(t, newClient) })
+	t.Run(name+"/CustomDialer", func(t *testing.T) { testCustomDialer(t, newClient) })
+	t.Run(name+"/Discovery", func(t *testing.T) { testDiscovery(t, newClient) })
+	t.Run(name+"/Resize", func(t *testing.T) { testResize(t, newClient) })
+	t.Run(name+"/Warmup", func(t *testing.T) { testWarmup(t, newClient) })
+	t.Run(name+"/Shutdown", func(t *testing.T) { testShutdown(t, newClient) })
+	t.Run(name+"/ShutdownDeadline", func(t *testing.T) { testShutdownDeadline(t, newClient) })
 	t.Run(name+"/BaseURLJoin", func(t *testing.T) { testBaseURLJoin(t, newClient) })
 	t.Run(name+"/DefaultSize", func(t *testing.T) { testDefaultSize(t, newClient) })
 	t.Run(name+"/DistributesAcrossConnections", func(t *testing.T) {
@@ -109,14 +170,13 @@ func testGetPost(t *testing.T, newClient ClientFactory) {
 			http.NotFound(w, r)
 		}
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -140,19 +200,43 @@ func testGetPost(t *testing.T, newClient ClientFactory) {
 	}
 }
 
+func testProtocol(t *testing.T, newClient ClientFactory, want string) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := p.Get(ctx, "/proto")
+	if err != nil {
+		t.Fatalf("GET /proto error: %v", err)
+	}
+	if got := string(resp.Body()); got != want {
+		t.Fatalf("negotiated %s, want %s", got, want)
+	}
+}
+
 func testContextTimeout(t *testing.T, newClient ClientFactory) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(200 * time.Millisecond)
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
@@ -162,10 +246,8 @@ func testContextTimeout(t *testing.T, newClient ClientFactory) {
 	if err == nil {
 		t.Fatalf("expected timeout error, got nil")
 	}
-	if !(errors.Is(err, context.DeadlineExceeded) ||
-		strings.Contains(err.Error(), "context deadline exceeded") ||
-		strings.Contains(err.Error(), "deadline exceeded")) {
-		t.Fatalf("want context timeout, got: %v", err)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, pool.Canceled) {
+		t.Fatalf("want context timeout classified as Canceled, got: %v", err)
 	}
 }
 
@@ -174,14 +256,13 @@ func testContextCancel(t *testing.T, newClient ClientFactory) {
 		time.Sleep(200 * time.Millisecond)
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -191,8 +272,7 @@ func testContextCancel(t *testing.T, newClient ClientFactory) {
 	if err == nil {
 		t.Fatalf("expected canceled error, got nil")
 	}
-	if !(errors.Is(err, context.Canceled) ||
-		strings.Contains(err.Error(), "context canceled")) {
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, pool.Canceled) {
 		t.Fatalf("want context canceled, got: %v", err)
 	}
 }
@@ -201,15 +281,14 @@ func testParallelNoRace(t *testing.T, newClient ClientFactory, workers int) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -239,19 +318,52 @@ func testCloseIdempotent(t *testing.T, newClient ClientFactory) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 
 	p.Close()
 	p.Close()
 }
 
+func testVerifiesTLSByDefault(t *testing.T, newClient ClientFactory) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = srv.URL
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := p.Get(ctx, "/ok"); err == nil {
+		t.Fatalf("expected certificate verification error with default config")
+	}
+}
+
+func testInsecureWarning(t *testing.T, newClient ClientFactory) {
+	var buf bytes.Buffer
+	cfg := config.TestConfig()
+	cfg.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	if !strings.Contains(buf.String(), "level=WARN") {
+		t.Fatalf("expected insecure TLS warning, got %q", buf.String())
+	}
+}
+
 func testBaseURLJoin(t *testing.T, newClient ClientFactory) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/hello" {
@@ -260,14 +372,13 @@ func testBaseURLJoin(t *testing.T, newClient ClientFactory) {
 		}
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -286,15 +397,14 @@ func testDefaultSize(t *testing.T, newClient ClientFactory) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.Size = 0
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -316,15 +426,14 @@ func testDistributesAcrossConnections(t *testing.T, newClient ClientFactory) {
 		mu.Unlock()
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -355,15 +464,14 @@ func testResponseHeaderTimeout(t *testing.T, newClient ClientFactory) {
 		time.Sleep(200 * time.Millisecond)
 		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
 	})
-	srv := newH1TLSServerWithHandler(h)
+	srv := newTLSServerWithHandler(h)
 	defer srv.Close()
 
-	cfg := config.DefaultConfig()
+	cfg := config.TestConfig()
 	cfg.BaseURL = srv.URL
-	cfg.InsecureSkipVerify = true
 	cfg.ResponseHeaderTimeout = 50 * time.Millisecond
 
-	p := newClient(cfg)
+	p := mustNew(t, newClient, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -374,8 +482,7 @@ func testResponseHeaderTimeout(t *testing.T, newClient ClientFactory) {
 		t.Fatalf("expected response header timeout, got nil")
 	}
 
-	if !strings.Contains(err.Error(), "timeout awaiting response headers") &&
-		!strings.Contains(err.Error(), "deadline exceeded") {
+	if !errors.Is(err, pool.HeaderTimeout) {
 		t.Fatalf("want header timeout, got: %v", err)
 	}
 }
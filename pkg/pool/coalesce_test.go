@@ -0,0 +1,201 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/coalesce"
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testCoalesce(t *testing.T, newClient ClientFactory) {
+	var hits atomic.Int64
+	var gateMu sync.Mutex
+	gate := make(chan struct{})
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		gateMu.Lock()
+		g := gate
+		gateMu.Unlock()
+		<-g
+		fmt.Fprintf(w, "%s %s %d", r.URL.Path, r.Header.Get("X-Lang"), n)
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 4
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// joined считает вызовы ключа: вызывающий присоединяется к общему вызову сразу после него.
+	var joined atomic.Int64
+	newCoalesced := func(t *testing.T, key coalesce.KeyFunc) *coalesce.Client {
+		c := coalesce.New(mustNew(t, newClient, cfg), coalesce.Options{Key: func(req pool.Request) (string, bool) {
+			joined.Add(1)
+			return key(req)
+		}})
+		t.Cleanup(c.Close)
+		return c
+	}
+	// burst отправляет reqs одновременно и отпускает сервер, когда все присоединились.
+	burst := func(t *testing.T, c pool.Client, reqs []pool.Request) ([]string, int64) {
+		t.Helper()
+		gateMu.Lock()
+		gate = make(chan struct{})
+		g := gate
+		gateMu.Unlock()
+		joined.Store(0)
+		before := hits.Load()
+
+		bodies := make([]string, len(reqs))
+		errs := make([]error, len(reqs))
+		var wg sync.WaitGroup
+		for i, req := range reqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := c.Do(ctx, req)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				bodies[i] = string(resp.Body())
+				// У каждого вызывающего своя копия тела.
+				resp.Body()[0] = 'X'
+				resp.Release()
+			}()
+		}
+		for joined.Load() < int64(len(reqs)) {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(g)
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return bodies, hits.Load() - before
+	}
+	repeat := func(n int, req pool.Request) []pool.Request {
+		reqs := make([]pool.Request, n)
+		for i := range reqs {
+			reqs[i] = req
+		}
+		return reqs
+	}
+
+	t.Run("Shared", func(t *testing.T) {
+		c := newCoalesced(t, coalesce.HeaderKey())
+		bodies, n := burst(t, c, repeat(50, pool.Request{Path: "/shared"}))
+		if n != 1 {
+			t.Fatalf("want one upstream call, got %d", n)
+		}
+		for _, b := range bodies {
+			if b != bodies[0] || b[0] != '/' {
+				t.Fatalf("callers must get equal, unmodified bodies: %q vs %q", b, bodies[0])
+			}
+		}
+
+		// После ответа следующий запрос идёт к апстриму заново.
+		if _, n := burst(t, c, repeat(1, pool.Request{Path: "/shared"})); n != 1 {
+			t.Fatalf("finished call must not be reused, got %d calls", n)
+		}
+	})
+
+	t.Run("Headers", func(t *testing.T) {
+		c := newCoalesced(t, coalesce.HeaderKey())
+		reqs := append(repeat(10, pool.Request{Path: "/lang", Header: http.Header{"X-Lang": {"en"}}}),
+			repeat(10, pool.Request{Path: "/lang", Header: http.Header{"X-Lang": {"de"}}})...)
+		bodies, n := burst(t, c, reqs)
+		if n != 2 {
+			t.Fatalf("different headers must not be coalesced, got %d calls", n)
+		}
+		if bodies[0][:len("/lang en")] != "/lang en" || bodies[10][:len("/lang de")] != "/lang de" {
+			t.Fatalf("wrong variants: %q, %q", bodies[0], bodies[10])
+		}
+	})
+
+	t.Run("CustomKey", func(t *testing.T) {
+		// Ключ только по X-Lang: X-Request-Id не мешает склейке.
+		c := newCoalesced(t, coalesce.HeaderKey("x-lang"))
+		reqs := make([]pool.Request, 10)
+		for i := range reqs {
+			reqs[i] = pool.Request{Path: "/custom", Header: http.Header{
+				"X-Lang":       {"en"},
+				"X-Request-Id": {fmt.Sprint(i)},
+			}}
+		}
+		if _, n := burst(t, c, reqs); n != 1 {
+			t.Fatalf("want one upstream call, got %d", n)
+		}
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		c := newCoalesced(t, coalesce.HeaderKey())
+		if _, n := burst(t, c, repeat(5, pool.Request{Method: http.MethodDelete, Path: "/delete"})); n != 5 {
+			t.Fatalf("DELETE must not be coalesced, got %d calls", n)
+		}
+	})
+
+	t.Run("CallerCanceled", func(t *testing.T) {
+		c := newCoalesced(t, coalesce.HeaderKey())
+		gateMu.Lock()
+		gate = make(chan struct{})
+		g := gate
+		gateMu.Unlock()
+		before := hits.Load()
+
+		type result struct {
+			body string
+			err  error
+		}
+		stay := make(chan result, 1)
+		go func() {
+			resp, err := c.Get(ctx, "/cancel")
+			if err != nil {
+				stay <- result{err: err}
+				return
+			}
+			defer resp.Release()
+			stay <- result{body: string(resp.Body())}
+		}()
+		for hits.Load() == before {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Ушедший вызывающий получает отмену, общий вызов продолжается для остальных.
+		leaveCtx, leave := context.WithCancel(ctx)
+		left := make(chan error, 1)
+		go func() {
+			_, err := c.Get(leaveCtx, "/cancel")
+			left <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		leave()
+		if err := <-left; !errors.Is(err, context.Canceled) || pool.KindOf(err) != pool.Canceled {
+			t.Fatalf("want Canceled, got %v", err)
+		}
+
+		close(g)
+		r := <-stay
+		if r.err != nil || r.body == "" {
+			t.Fatalf("remaining caller: %q, %v", r.body, r.err)
+		}
+		if n := hits.Load() - before; n != 1 {
+			t.Fatalf("want one upstream call, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/compress"
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testCompression(t *testing.T, newClient ClientFactory) {
+	// Больше streamBufferSize fiberpool, чтобы Stream шёл потоком и после распаковки.
+	payload := strings.Repeat("compressible payload ", 10<<10)
+	bomb, err := compress.Encode(nil, config.EncodingGzip, make([]byte, 8<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		switch r.URL.Path {
+		case "/bomb":
+			w.Header().Set("Content-Encoding", config.EncodingGzip)
+			_, _ = w.Write(bomb)
+		case "/echo":
+			// Возвращает тело запроса распакованным и сообщает, в чём оно пришло.
+			enc := r.Header.Get("Content-Encoding")
+			var body io.Reader = r.Body
+			if enc != "" {
+				dec, err := compress.NewReader(enc, r.Body, 1<<30)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				defer dec.Close()
+				body = dec
+			}
+			b, err := io.ReadAll(body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-Content-Encoding", enc)
+			_, _ = w.Write(b)
+		default:
+			enc := r.URL.Query().Get("enc")
+			if enc == "" || !strings.Contains(r.Header.Get("Accept-Encoding"), enc) {
+				_, _ = io.WriteString(w, payload)
+				return
+			}
+			b, err := compress.Encode(nil, enc, []byte(payload))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Encoding", enc)
+			w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+			_, _ = w.Write(b)
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	newPool := func(t *testing.T, cm config.Compression) pool.Client {
+		cfg := config.TestConfig()
+		cfg.BaseURL = srv.URL
+		cfg.Size = 2
+		cfg.Compression = cm
+		p := mustNew(t, newClient, cfg)
+		t.Cleanup(p.Close)
+		return p
+	}
+
+	for _, enc := range []string{config.EncodingGzip, config.EncodingBrotli, config.EncodingZstd} {
+		t.Run("Accept/"+enc, func(t *testing.T) {
+			p := newPool(t, config.Compression{Accept: []string{enc, config.EncodingGzip}})
+			path := "/data?enc=" + enc
+
+			resp, err := p.Get(ctx, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Header().Get("X-Accept-Encoding"); got != enc+", gzip" {
+				t.Fatalf("Accept-Encoding: got %q", got)
+			}
+			if string(resp.Body()) != payload || resp.Header().Get("Content-Encoding") != "" {
+				t.Fatalf("get: body of %d bytes, Content-Encoding %q", len(resp.Body()), resp.Header().Get("Content-Encoding"))
+			}
+
+			resp, err = p.Do(ctx, pool.Request{Path: path})
+			if err != nil || string(resp.Body()) != payload {
+				t.Fatalf("do: %v, body of %d bytes", err, len(resp.Body()))
+			}
+
+			sr, err := p.Stream(ctx, pool.Request{Path: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(sr.Body)
+			_ = sr.Body.Close()
+			if err != nil || string(b) != payload || sr.Header.Get("Content-Encoding") != "" {
+				t.Fatalf("stream: %v, body of %d bytes, Content-Encoding %q", err, len(b), sr.Header.Get("Content-Encoding"))
+			}
+		})
+	}
+
+	t.Run("Bomb", func(t *testing.T) {
+		p := newPool(t, config.Compression{Accept: []string{config.EncodingGzip}, MaxDecompressedSize: 1 << 20})
+
+		_, err := p.Get(ctx, "/bomb")
+		var tl *pool.BodyTooLargeError
+		if !errors.As(err, &tl) || tl.Limit != 1<<20 || pool.KindOf(err) != pool.BodyTooLarge {
+			t.Fatalf("get: want BodyTooLargeError, got %v (kind %s)", err, pool.KindOf(err))
+		}
+
+		sr, err := p.Stream(ctx, pool.Request{Path: "/bomb"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := io.Copy(io.Discard, sr.Body)
+		_ = sr.Body.Close()
+		if !errors.Is(err, pool.BodyTooLarge) || n != 1<<20 {
+			t.Fatalf("stream: want BodyTooLarge after %d bytes, got %v after %d", 1<<20, err, n)
+		}
+
+		// Пул после бомбы работает.
+		if _, err := p.Get(ctx, "/data"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Request", func(t *testing.T) {
+		p := newPool(t, config.Compression{Request: config.EncodingZstd, RequestMinSize: 1 << 10})
+		big := strings.Repeat("x", 4<<10)
+
+		resp, err := p.Post(ctx, "/echo", map[string]string{"s": big})
+		if err != nil || !strings.Contains(string(resp.Body()), big) || resp.Header().Get("X-Content-Encoding") != config.EncodingZstd {
+			t.Fatalf("post: %v, %d bytes, encoding %q", err, len(resp.Body()), resp.Header().Get("X-Content-Encoding"))
+		}
+
+		resp, err = p.Post(ctx, "/echo", map[string]string{"s": "small"})
+		if err != nil || !strings.Contains(string(resp.Body()), "small") || resp.Header().Get("X-Content-Encoding") != "" {
+			t.Fatalf("small post must not be compressed: %v %q %q", err, resp.Body(), resp.Header().Get("X-Content-Encoding"))
+		}
+
+		// Длина неизвестна — сжимается всегда.
+		resp, err = p.Do(ctx, pool.Request{Method: http.MethodPut, Path: "/echo", Body: io.MultiReader(strings.NewReader("a"), strings.NewReader("b"))})
+		if err != nil || string(resp.Body()) != "ab" || resp.Header().Get("X-Content-Encoding") != config.EncodingZstd {
+			t.Fatalf("do: %v %q %q", err, resp.Body(), resp.Header().Get("X-Content-Encoding"))
+		}
+
+		resp, err = p.Do(ctx, pool.Request{Method: http.MethodPut, Path: "/echo", Body: strings.NewReader("tiny"), ContentLength: 4})
+		if err != nil || string(resp.Body()) != "tiny" || resp.Header().Get("X-Content-Encoding") != "" {
+			t.Fatalf("do small: %v %q %q", err, resp.Body(), resp.Header().Get("X-Content-Encoding"))
+		}
+
+		sr, err := p.Stream(ctx, pool.Request{Method: http.MethodPut, Path: "/echo", Body: strings.NewReader(big), ContentLength: int64(len(big))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(sr.Body)
+		_ = sr.Body.Close()
+		if err != nil || string(b) != big || sr.Header.Get("X-Content-Encoding") != config.EncodingZstd {
+			t.Fatalf("stream: %v, %d bytes, encoding %q", err, len(b), sr.Header.Get("X-Content-Encoding"))
+		}
+
+		// Уже сжатое вызывающим тело не сжимается повторно.
+		pre, _ := compress.Encode(nil, config.EncodingGzip, []byte(big))
+		resp, err = p.Do(ctx, pool.Request{
+			Method: http.MethodPut, Path: "/echo",
+			Header: http.Header{"Content-Encoding": {config.EncodingGzip}},
+			Body:   strings.NewReader(string(pre)), ContentLength: int64(len(pre)),
+		})
+		if err != nil || string(resp.Body()) != big || resp.Header().Get("X-Content-Encoding") != config.EncodingGzip {
+			t.Fatalf("precompressed: %v, %d bytes, encoding %q", err, len(resp.Body()), resp.Header().Get("X-Content-Encoding"))
+		}
+	})
+}
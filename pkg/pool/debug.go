@@ -0,0 +1,93 @@
+package pool
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// DebugHandler отдаёт состояние зарегистрированных пулов (по аналогии с expvar/pprof).
+// По умолчанию — HTML-таблица; JSON при ?format=json или Accept: application/json.
+// Параметр ?name= ограничивает вывод одним пулом.
+//
+//	mux.Handle("/debug/httppool", pool.DebugHandler())
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(serveDebug)
+}
+
+func serveDebug(w http.ResponseWriter, r *http.Request) {
+	snaps := Snapshots()
+	if name := r.URL.Query().Get("name"); name != "" {
+		filtered := snaps[:0]
+		for _, s := range snaps {
+			if s.Name == name {
+				filtered = append(filtered, s)
+			}
+		}
+		snaps = filtered
+	}
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(snaps)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = debugTmpl.Execute(w, snaps)
+}
+
+var debugTmpl = template.Must(template.New("httppool").Parse(`<!DOCTYPE html>
+<html>
+<head><title>httppool</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #999; padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+{{- range .}}
+<h2>{{.Name}} ({{.Backend}})</h2>
+<h3>config</h3>
+<table>
+<tr><th>BaseURL</th><td>{{.Config.BaseURL}}</td></tr>
+<tr><th>Size</th><td>{{.Config.Size}}</td></tr>
+<tr><th>RequestTimeout</th><td>{{.Config.RequestTimeout}}</td></tr>
+<tr><th>DialTimeout</th><td>{{.Config.DialTimeout}}</td></tr>
+<tr><th>TlsTimeout</th><td>{{.Config.TlsTimeout}}</td></tr>
+<tr><th>IdleConnTimeout</th><td>{{.Config.IdleConnTimeout}}</td></tr>
+<tr><th>MaxConnsPerHost</th><td>{{.Config.MaxConnsPerHost}}</td></tr>
+<tr><th>InsecureSkipVerify</th><td>{{.Config.InsecureSkipVerify}}</td></tr>
+<tr><th>ResponseHeaderTimeout</th><td>{{.Config.ResponseHeaderTimeout}}</td></tr>
+</table>
+<h3>endpoints</h3>
+<table>
+<tr><th>url</th><th>addr</th><th>weight</th><th>members</th><th>healthy</th><th>consecutive failures</th><th>down until</th></tr>
+{{- range .Endpoints}}
+<tr><td>{{.URL}}</td><td>{{.Addr}}</td><td>{{.Weight}}</td><td>{{.Members}}</td><td>{{.Healthy}}</td><td>{{.ConsecutiveFailures}}</td><td>{{if not .DownUntil.IsZero}}{{.DownUntil.Format "15:04:05.000"}}{{end}}</td></tr>
+{{- end}}
+</table>
+<h3>members</h3>
+<table>
+<tr><th>#</th><th>endpoint</th><th>addr</th><th>in flight</th><th>requests</th><th>failures</th><th>last used</th></tr>
+{{- range .Members}}
+<tr><td>{{.Index}}</td><td>{{.Endpoint}}</td><td>{{.Addr}}</td><td>{{.InFlight}}</td><td>{{.Requests}}</td><td>{{.Failures}}</td><td>{{if not .LastUsed.IsZero}}{{.LastUsed.Format "15:04:05.000"}}{{end}}</td></tr>
+{{- end}}
+</table>
+<h3>recent errors</h3>
+<table>
+<tr><th>time</th><th>member</th><th>path</th><th>error</th></tr>
+{{- range .RecentErrors}}
+<tr><td>{{.Time.Format "15:04:05.000"}}</td><td>{{.Member}}</td><td>{{.Path}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>no pools registered</p>
+{{- end}}
+</body>
+</html>
+`))
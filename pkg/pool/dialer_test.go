@@ -0,0 +1,99 @@
+package pool_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+func testUnixSocket(t *testing.T, newClient ClientFactory) {
+	// Короткий путь: длина пути unix-сокета ограничена ~100 байтами.
+	dir, err := os.MkdirTemp("", "pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	sock := filepath.Join(dir, "s.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	var conns atomic.Int64
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Host + r.URL.Path))
+		}),
+		ConnState: func(_ net.Conn, st http.ConnState) {
+			if st == http.StateNew {
+				conns.Add(1)
+			}
+		},
+	}
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = "unix://" + sock
+	cfg.Size = 4
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 12; i++ {
+		resp, err := p.Get(ctx, "/stats")
+		if err != nil {
+			t.Fatalf("GET over unix socket: %v", err)
+		}
+		if got := string(resp.Body()); got != "localhost/stats" {
+			t.Fatalf("unexpected body %q", got)
+		}
+	}
+	if got := conns.Load(); got != 4 {
+		t.Fatalf("want one connection per member (4), got %d", got)
+	}
+}
+
+type countingDialer struct {
+	net.Dialer
+	dials atomic.Int64
+}
+
+func (d *countingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.dials.Add(1)
+	return d.Dialer.DialContext(ctx, network, addr)
+}
+
+func testCustomDialer(t *testing.T, newClient ClientFactory) {
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	d := &countingDialer{}
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 3
+	cfg.Dialer = d
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 9; i++ {
+		if _, err := p.Get(ctx, "/"); err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+	}
+	if got := d.dials.Load(); got != 3 {
+		t.Fatalf("want custom dialer used once per member (3), got %d", got)
+	}
+}
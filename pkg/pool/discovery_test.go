@@ -0,0 +1,75 @@
+package pool_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/discovery"
+)
+
+func testDiscovery(t *testing.T, newClient ClientFactory) {
+	named := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name))
+		})
+	}
+	a := newTLSServerWithHandler(named("a"))
+	defer a.Close()
+	b := newTLSServerWithHandler(named("b"))
+	defer b.Close()
+
+	path := filepath.Join(t.TempDir(), "endpoints.json")
+	write := func(data string, mod time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write(fmt.Sprintf("[%q]", a.URL), now)
+
+	cfg := config.TestConfig()
+	cfg.Size = 4
+	cfg.Discovery = discovery.File(path, 10*time.Millisecond, nil)
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hits := func(n int) map[string]int {
+		out := map[string]int{}
+		for i := 0; i < n; i++ {
+			resp, err := p.Get(ctx, "/")
+			if err != nil {
+				out["error"]++
+				continue
+			}
+			out[string(resp.Body())]++
+		}
+		return out
+	}
+
+	if got := hits(8); got["a"] != 8 {
+		t.Fatalf("want all requests on a, got %v", got)
+	}
+
+	write(fmt.Sprintf(`[%q, {"url": %q, "weight": 3}]`, a.URL, b.URL), now.Add(time.Second))
+	waitFor(t, ctx, func() bool {
+		got := hits(8)
+		return got["a"] == 2 && got["b"] == 6
+	}, "members to follow discovered endpoints")
+
+	write(fmt.Sprintf("[%q]", b.URL), now.Add(2*time.Second))
+	waitFor(t, ctx, func() bool { return hits(8)["b"] == 8 }, "removed endpoint to be drained")
+}
@@ -0,0 +1,156 @@
+package pool_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+type fakeResolver struct {
+	mu    sync.Mutex
+	addrs map[string][]string
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addrs, ok := r.addrs[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return append([]string(nil), addrs...), nil
+}
+
+func (r *fakeResolver) set(host string, addrs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.addrs == nil {
+		r.addrs = map[string][]string{}
+	}
+	r.addrs[host] = addrs
+}
+
+// newDNSServers поднимает по TLS-серверу на каждом IP на одном и том же порту.
+// Сервер отвечает своим IP и запоминает SNI и Host, с которыми к нему пришли.
+func newDNSServers(t *testing.T, ips ...string) (port int, seen func() (sni, host map[string]bool)) {
+	t.Helper()
+	var mu sync.Mutex
+	snis, hosts := map[string]bool{}, map[string]bool{}
+
+	for _, ip := range ips {
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err != nil {
+			t.Skipf("listen on %s: %v", ip, err)
+		}
+		port = l.Addr().(*net.TCPAddr).Port
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hosts[r.Host] = true
+			mu.Unlock()
+			_, _ = w.Write([]byte(ip))
+		}))
+		srv.Listener.Close()
+		srv.Listener = l
+		srv.TLS = &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			snis[hello.ServerName] = true
+			mu.Unlock()
+			return nil, nil
+		}}
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+	}
+
+	return port, func() (map[string]bool, map[string]bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return snis, hosts
+	}
+}
+
+func testDNS(t *testing.T, newClient ClientFactory) {
+	port, seen := newDNSServers(t, "127.0.0.1", "::1")
+
+	res := &fakeResolver{}
+	res.set("pool.test", "127.0.0.1")
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = fmt.Sprintf("https://pool.test:%d", port)
+	cfg.Size = 4
+	cfg.DNSRefresh = 20 * time.Millisecond
+	cfg.Resolver = res
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hits := func(n int) map[string]int {
+		out := map[string]int{}
+		for i := 0; i < n; i++ {
+			resp, err := p.Get(ctx, "/")
+			if err != nil {
+				out["error"]++
+				continue
+			}
+			out[string(resp.Body())]++
+		}
+		return out
+	}
+
+	if got := hits(8); got["127.0.0.1"] != 8 {
+		t.Fatalf("want all requests on 127.0.0.1, got %v", got)
+	}
+
+	res.set("pool.test", "127.0.0.1", "::1")
+	waitFor(t, ctx, func() bool {
+		got := hits(8)
+		return got["127.0.0.1"] == 4 && got["::1"] == 4
+	}, "members to spread over both addresses")
+
+	res.set("pool.test", "::1")
+	waitFor(t, ctx, func() bool { return hits(8)["::1"] == 8 }, "removed address to be drained")
+
+	snis, hosts := seen()
+	if len(snis) != 1 || !snis["pool.test"] {
+		t.Fatalf("want SNI pool.test only, got %v", snis)
+	}
+	if want := fmt.Sprintf("pool.test:%d", port); len(hosts) != 1 || !hosts[want] {
+		t.Fatalf("want Host %s only, got %v", want, hosts)
+	}
+
+	// Адрес DNS-режима подменяет только хост BaseURL: абсолютный URL идёт на свой адрес.
+	other := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("other"))
+	}))
+	defer other.Close()
+	resp, err := p.Get(ctx, other.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(resp.Body()); got != "other" {
+		t.Fatalf("absolute URL must not be redirected to the resolved address, got %q", got)
+	}
+}
+
+func waitFor(t *testing.T, ctx context.Context, cond func() bool, what string) {
+	t.Helper()
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %s", what)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package pool_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+func testMultiEndpoint(t *testing.T, newClient ClientFactory) {
+	named := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/who" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(name))
+		})
+	}
+	a := newTLSServerWithHandler(named("a"))
+	defer a.Close()
+	b := newTLSServerWithHandler(named("b"))
+	defer b.Close()
+
+	cfg := config.TestConfig()
+	cfg.Size = 4
+	cfg.Endpoints = []config.Endpoint{{URL: a.URL, Weight: 1}, {URL: b.URL, Weight: 3}}
+	cfg.FailoverThreshold = 2
+	cfg.FailoverCooldown = time.Minute
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts := map[string]int{}
+	for i := 0; i < 40; i++ {
+		resp, err := p.Get(ctx, "/who")
+		if err != nil {
+			t.Fatalf("GET /who: %v", err)
+		}
+		counts[string(resp.Body())]++
+	}
+	if counts["a"] != 10 || counts["b"] != 30 {
+		t.Fatalf("want a=10 b=30 by weight, got %v", counts)
+	}
+
+	a.Close()
+
+	var fails int
+	for i := 0; i < 20; i++ {
+		resp, err := p.Get(ctx, "/who")
+		if err != nil {
+			fails++
+			continue
+		}
+		if string(resp.Body()) != "b" {
+			t.Fatalf("unexpected responder %q", resp.Body())
+		}
+	}
+	if fails > cfg.FailoverThreshold {
+		t.Fatalf("want at most %d failures before failover, got %d", cfg.FailoverThreshold, fails)
+	}
+}
@@ -0,0 +1,203 @@
+package pool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Kind — класс ошибки запроса, одинаковый для всех бэкендов. Kind сам реализует error,
+// поэтому проверка пишется как errors.Is(err, pool.HeaderTimeout).
+type Kind int
+
+const (
+	Unknown Kind = iota
+	// DialTimeout — не удалось установить TCP-соединение за DialTimeout.
+	DialTimeout
+	// DialFailed — соединение не установлено по другой причине: отказ, недоступность, DNS.
+	DialFailed
+	// TLSHandshake — рукопожатие не удалось: сертификат, пин, таймаут TlsTimeout.
+	TLSHandshake
+	// HeaderTimeout — сервер не прислал заголовки ответа за ResponseHeaderTimeout.
+	HeaderTimeout
+	// ReadTimeout — таймаут после установки соединения: запрос целиком или чтение тела.
+	ReadTimeout
+	// ConnReset — соединение (или поток h2) разорвано сервером посреди запроса.
+	ConnReset
+	// Canceled — ctx вызывающего отменён или истёк.
+	Canceled
+	// PoolClosed — пул закрыт или останавливается (Shutdown).
+	PoolClosed
+	// Saturated — свободного соединения не нашлось до истечения ctx или лимита ожидания.
+	Saturated
+	// BodyTooLarge — тело ответа больше допустимого, см. BodyTooLargeError.
+	BodyTooLarge
+)
+
+var kindNames = [...]string{
+	Unknown:       "unknown",
+	DialTimeout:   "dial timeout",
+	DialFailed:    "dial failed",
+	TLSHandshake:  "tls handshake",
+	HeaderTimeout: "header timeout",
+	ReadTimeout:   "read timeout",
+	ConnReset:     "connection reset",
+	Canceled:      "canceled",
+	PoolClosed:    "pool closed",
+	Saturated:     "saturated",
+	BodyTooLarge:  "body too large",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+func (k Kind) Error() string { return "httpclientpool: " + k.String() }
+
+// Error — ошибка запроса через пул: класс, номер члена пула (-1, если член не был выбран)
+// и исходная ошибка бэкенда. errors.Is видит и Kind, и исходную ошибку (context.Canceled,
+// ErrClosed, ...), errors.As — и *Error, и типы исходной ошибки.
+type Error struct {
+	Kind   Kind
+	Member int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Member < 0 {
+		return fmt.Sprintf("httpclientpool: %s: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("httpclientpool: %s (member %d): %v", e.Kind, e.Member, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k == e.Kind
+}
+
+// BodyTooLargeError — тело ответа больше Limit байт: config.MaxResponseBodySize или, после
+// распаковки, config.Compression.MaxDecompressedSize. Чтение прервано, остаток тела не читался.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("httpclientpool: response body exceeds %d bytes", e.Limit)
+}
+
+// ErrBodyReadTimeout — тело ответа не дочитано за config.BodyReadTimeout (класс ReadTimeout).
+var ErrBodyReadTimeout = errors.New("httpclientpool: body read timeout")
+
+// KindOf возвращает класс ошибки: Kind из *Error в цепочке, иначе — Classify.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Classify(err)
+}
+
+// NewError оборачивает ошибку бэкенда в *Error. Если err уже *Error (бэкенд
+// классифицировал её сам), у неё проставляется член пула. Ошибка при отменённом
+// или истёкшем ctx вызывающего — всегда Canceled: таймаут транспорта тут вторичен.
+func NewError(ctx context.Context, member int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Kind: Classify(err), Member: member, Err: err}
+	} else if e.Member < 0 {
+		e.Member = member
+	}
+	if ctx != nil && ctx.Err() != nil && e.Kind != PoolClosed {
+		e.Kind = Canceled
+	}
+	return e
+}
+
+// Classify определяет класс ошибки net/http, crypto/tls, net и context.
+// net/http не экспортирует часть своих ошибок, их приходится узнавать по тексту.
+func Classify(err error) Kind {
+	if err == nil {
+		return Unknown
+	}
+	var k Kind
+	if errors.As(err, &k) {
+		return k
+	}
+	msg := err.Error()
+
+	var tl *BodyTooLargeError
+	switch {
+	case errors.As(err, &tl):
+		return BodyTooLarge
+	case errors.Is(err, ErrClosed):
+		return PoolClosed
+	case errors.Is(err, ErrBodyReadTimeout):
+		return ReadTimeout
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case isTLS(err, msg):
+		return TLSHandshake
+	case strings.Contains(msg, "timeout awaiting response headers"),
+		strings.Contains(msg, "Client.Timeout exceeded while awaiting headers"):
+		return HeaderTimeout
+	}
+
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		if op.Timeout() {
+			return DialTimeout
+		}
+		return DialFailed
+	}
+	var dns *net.DNSError
+	if errors.As(err, &dns) {
+		if dns.Timeout() {
+			return DialTimeout
+		}
+		return DialFailed
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF),
+		strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"),
+		strings.Contains(msg, "server closed idle connection"), strings.Contains(msg, "stream error"):
+		return ConnReset
+	case errors.Is(err, context.DeadlineExceeded):
+		return ReadTimeout
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return ReadTimeout
+	}
+	return Unknown
+}
+
+func isTLS(err error, msg string) bool {
+	var (
+		cv *tls.CertificateVerificationError
+		rh tls.RecordHeaderError
+		al tls.AlertError
+		ua x509.UnknownAuthorityError
+		he x509.HostnameError
+		ci x509.CertificateInvalidError
+	)
+	return errors.As(err, &cv) || errors.As(err, &rh) || errors.As(err, &al) ||
+		errors.As(err, &ua) || errors.As(err, &he) || errors.As(err, &ci) ||
+		strings.Contains(msg, "tls: ") || strings.Contains(msg, "x509: ") ||
+		strings.Contains(msg, "TLS handshake timeout")
+}
@@ -0,0 +1,174 @@
+package pool_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		want pool.Kind
+	}{
+		{errors.New("boom"), pool.Unknown},
+		{pool.ErrClosed, pool.PoolClosed},
+		{fmt.Errorf("get: %w", context.Canceled), pool.Canceled},
+		{context.DeadlineExceeded, pool.ReadTimeout},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: timeoutErr{}}, pool.DialTimeout},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, pool.DialFailed},
+		{&net.DNSError{Err: "no such host", Name: "x", IsNotFound: true}, pool.DialFailed},
+		{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, pool.ConnReset},
+		{&net.OpError{Op: "read", Net: "tcp", Err: timeoutErr{}}, pool.ReadTimeout},
+		{io.ErrUnexpectedEOF, pool.ConnReset},
+		{errors.New("net/http: TLS handshake timeout"), pool.TLSHandshake},
+		{&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, pool.TLSHandshake},
+		{errors.New("net/http: timeout awaiting response headers"), pool.HeaderTimeout},
+		{fmt.Errorf("wrapped: %w", pool.Saturated), pool.Saturated},
+	}
+	for _, c := range cases {
+		if got := pool.Classify(c.err); got != c.want {
+			t.Errorf("Classify(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	err := pool.NewError(context.Background(), 3, io.EOF)
+	var pe *pool.Error
+	if !errors.As(err, &pe) || pe.Member != 3 || pe.Kind != pool.ConnReset {
+		t.Fatalf("want ConnReset on member 3, got %#v", err)
+	}
+	if !errors.Is(err, io.EOF) || !errors.Is(err, pool.ConnReset) || errors.Is(err, pool.ReadTimeout) {
+		t.Fatalf("errors.Is mismatch for %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.NewError(ctx, 0, &net.OpError{Op: "read", Err: timeoutErr{}}); !errors.Is(err, pool.Canceled) {
+		t.Fatalf("error under canceled ctx must be Canceled, got %v", err)
+	}
+	if pool.NewError(ctx, 0, nil) != nil {
+		t.Fatalf("nil error must stay nil")
+	}
+}
+
+func testErrors(t *testing.T, newClient ClientFactory) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	wantKind := func(t *testing.T, err error, kind pool.Kind, member bool) {
+		t.Helper()
+		var pe *pool.Error
+		if !errors.As(err, &pe) {
+			t.Fatalf("want *pool.Error, got %T: %v", err, err)
+		}
+		if pe.Kind != kind || !errors.Is(err, kind) {
+			t.Fatalf("want %s, got %s: %v", kind, pe.Kind, err)
+		}
+		if member && pe.Member < 0 {
+			t.Fatalf("want member index, got %d", pe.Member)
+		}
+	}
+
+	t.Run("DialFailed", func(t *testing.T) {
+		cfg := config.TestConfig()
+		cfg.BaseURL = deadURL(t)
+		cfg.Size = 1
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+
+		_, err := p.Get(ctx, "/")
+		wantKind(t, err, pool.DialFailed, true)
+	})
+
+	t.Run("TLSHandshake", func(t *testing.T) {
+		srv := newTLSServerWithHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer srv.Close()
+
+		cfg := config.TestConfig()
+		cfg.BaseURL = srv.URL
+		cfg.InsecureSkipVerify = false
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+
+		_, err := p.Get(ctx, "/")
+		wantKind(t, err, pool.TLSHandshake, true)
+	})
+
+	t.Run("ConnReset", func(t *testing.T) {
+		srv := newTLSServerWithHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		defer srv.Close()
+
+		cfg := config.TestConfig()
+		cfg.BaseURL = srv.URL
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+
+		_, err := p.Get(ctx, "/")
+		wantKind(t, err, pool.ConnReset, true)
+	})
+
+	t.Run("Saturated", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/block" {
+				<-release
+			}
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		cfg := config.TestConfig()
+		cfg.BaseURL = srv.URL
+		cfg.Size = 1
+		cfg.MaxConnsPerHost = 1
+		cfg.MaxConcurrentStreams = 1
+		p := mustNew(t, newClient, cfg)
+		defer p.Close()
+
+		go func() { _, _ = p.Get(ctx, "/block") }()
+		var err error
+		for i := 0; i < 100; i++ {
+			wctx, wcancel := context.WithTimeout(ctx, 30*time.Millisecond)
+			_, err = p.Get(wctx, "/")
+			wcancel()
+			if errors.Is(err, pool.Saturated) {
+				break
+			}
+		}
+		wantKind(t, err, pool.Saturated, true)
+	})
+
+	t.Run("PoolClosed", func(t *testing.T) {
+		cfg := config.TestConfig()
+		cfg.BaseURL = "https://127.0.0.1:1"
+		p := mustNew(t, newClient, cfg)
+		p.Close()
+
+		_, err := p.Get(ctx, "/")
+		wantKind(t, err, pool.PoolClosed, false)
+		if !errors.Is(err, pool.ErrClosed) {
+			t.Fatalf("PoolClosed must still match pool.ErrClosed: %v", err)
+		}
+	})
+}
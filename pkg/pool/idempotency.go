@@ -0,0 +1,62 @@
+package pool
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"httpclientpool/pkg/config"
+)
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey закрепляет ключ идемпотентности за ctx: все POST и PATCH с этим ctx
+// уйдут с ним, даже если config.Idempotency.Enabled выключен. Так повтор вызова на уровне
+// приложения (в том числе на другой член пула или пул) несёт тот же ключ, что и первая попытка.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKey — ключ, закреплённый за ctx через WithIdempotencyKey.
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// NewIdempotencyKey — ключ по умолчанию: случайный UUID v4.
+func NewIdempotencyKey() string { return uuid.NewString() }
+
+// WithIdempotency добавляет к POST и PATCH заголовок ключа идемпотентности: ключ из ctx,
+// иначе (при cfg.Enabled) новый от cfg.Generator. Заголовок, заданный вызывающим, не
+// трогается. Бэкенды вызывают её один раз на вызов, до выбора члена пула. Своих повторов
+// и хеджирования у пула нет: новый вызов получает новый ключ, если он не закреплён за ctx
+// через WithIdempotencyKey. Header запроса копируется.
+func WithIdempotency(ctx context.Context, cfg config.Idempotency, req Request) Request {
+	if m := strings.ToUpper(req.Method); m != http.MethodPost && m != http.MethodPatch {
+		return req
+	}
+	name := cfg.HeaderName()
+	if req.Header.Get(name) != "" {
+		return req
+	}
+	key, ok := IdempotencyKey(ctx)
+	if !ok {
+		if !cfg.Enabled {
+			return req
+		}
+		gen := cfg.Generator
+		if gen == nil {
+			gen = NewIdempotencyKey
+		}
+		key = gen()
+	}
+	h := req.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set(name, key)
+	req.Header = h
+	return req
+}
@@ -0,0 +1,148 @@
+package pool_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testIdempotency(t *testing.T, newClient ClientFactory) {
+	var mu sync.Mutex
+	var keys []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key")+"|"+r.Header.Get("X-Request-Key"))
+		mu.Unlock()
+		_, _ = io.WriteString(w, "ok")
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// sent выполняет f и возвращает пары "Idempotency-Key|X-Request-Key" полученных сервером запросов.
+	sent := func(t *testing.T, f func()) []string {
+		t.Helper()
+		mu.Lock()
+		keys = nil
+		mu.Unlock()
+		f()
+		mu.Lock()
+		defer mu.Unlock()
+		return keys
+	}
+	check := func(t *testing.T, resp pool.Response, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Release()
+	}
+	newPool := func(t *testing.T, idem config.Idempotency) pool.Client {
+		cfg := config.TestConfig()
+		cfg.BaseURL = srv.URL
+		cfg.Size = 2
+		cfg.Idempotency = idem
+		p := mustNew(t, newClient, cfg)
+		t.Cleanup(p.Close)
+		return p
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		p := newPool(t, config.Idempotency{})
+		got := sent(t, func() {
+			resp, err := p.Post(ctx, "/pay", map[string]int{"amount": 1})
+			check(t, resp, err)
+		})
+		if got[0] != "|" {
+			t.Fatalf("key must not be sent when disabled, got %q", got[0])
+		}
+	})
+
+	t.Run("Generated", func(t *testing.T) {
+		p := newPool(t, config.Idempotency{Enabled: true})
+		got := sent(t, func() {
+			resp, err := p.Post(ctx, "/pay", map[string]int{"amount": 1})
+			check(t, resp, err)
+			resp, err = p.Post(ctx, "/pay", map[string]int{"amount": 1})
+			check(t, resp, err)
+			resp, err = p.Do(ctx, pool.Request{Method: http.MethodPatch, Path: "/pay", Body: strings.NewReader("{}")})
+			check(t, resp, err)
+			resp, err = p.Get(ctx, "/pay")
+			check(t, resp, err)
+			sr, err := p.Stream(ctx, pool.Request{Method: http.MethodPost, Path: "/pay", Body: strings.NewReader("{}")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = io.Copy(io.Discard, sr.Body)
+			_ = sr.Body.Close()
+		})
+		for _, i := range []int{0, 1, 2, 4} {
+			key, _, _ := strings.Cut(got[i], "|")
+			if _, err := uuid.Parse(key); err != nil {
+				t.Fatalf("request %d: want UUID key, got %q", i, key)
+			}
+		}
+		if got[0] == got[1] {
+			t.Fatal("each logical call must get its own key")
+		}
+		if got[3] != "|" {
+			t.Fatalf("GET must not carry a key, got %q", got[3])
+		}
+
+		// Заголовок вызывающего не перезаписывается.
+		got = sent(t, func() {
+			resp, err := p.Do(ctx, pool.Request{Method: http.MethodPost, Path: "/pay",
+				Header: http.Header{"Idempotency-Key": {"mine"}}})
+			check(t, resp, err)
+		})
+		if got[0] != "mine|" {
+			t.Fatalf("caller's key must be kept, got %q", got[0])
+		}
+	})
+
+	t.Run("HeaderAndGenerator", func(t *testing.T) {
+		var n atomic.Int64
+		p := newPool(t, config.Idempotency{Enabled: true, Header: "X-Request-Key", Generator: func() string {
+			return "k" + string(rune('0'+n.Add(1)))
+		}})
+		got := sent(t, func() {
+			resp, err := p.Post(ctx, "/pay", map[string]int{"amount": 1})
+			check(t, resp, err)
+		})
+		if got[0] != "|k1" {
+			t.Fatalf("want custom header and generator, got %q", got[0])
+		}
+	})
+
+	t.Run("PinnedAcrossRetries", func(t *testing.T) {
+		// Повтор вызова приложением: ключ закреплён за ctx и одинаков во всех попытках,
+		// в том числе через другой пул.
+		p, other := newPool(t, config.Idempotency{Enabled: true}), newPool(t, config.Idempotency{})
+		retryCtx := pool.WithIdempotencyKey(ctx, pool.NewIdempotencyKey())
+		got := sent(t, func() {
+			for _, c := range []pool.Client{p, p, other} {
+				resp, err := c.Post(retryCtx, "/pay", map[string]int{"amount": 1})
+				check(t, resp, err)
+			}
+		})
+		want, _ := pool.IdempotencyKey(retryCtx)
+		for i, k := range got {
+			if k != want+"|" {
+				t.Fatalf("attempt %d: got %q, want %q", i, k, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Codec — JSON-кодек для GetJSON/PostJSON: encoding/json или совместимая по формату
+// более быстрая реализация. GetAs/PostAs принимают и кодек другого формата: если он
+// реализует ContentType() string, PostAs отправляет тело с этим Content-Type.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec — encoding/json.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// DefaultCodec используется GetJSON и PostJSON. Заменять — при старте программы, до запросов.
+var DefaultCodec Codec = JSONCodec{}
+
+// errorBodySnippet — сколько байт тела ответа сохраняется в HTTPError.
+const errorBodySnippet = 512
+
+// HTTPError — ответ со статусом вне 2xx.
+type HTTPError struct {
+	StatusCode int
+	// Body — начало тела ответа (не больше 512 байт).
+	Body   []byte
+	Header http.Header
+}
+
+func (e *HTTPError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("httpclientpool: unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("httpclientpool: unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// GetJSON выполняет GET и декодирует тело 2xx-ответа в T через DefaultCodec.
+// На остальные статусы возвращает *HTTPError.
+func GetJSON[T any](ctx context.Context, c Client, path string) (T, error) {
+	return GetAs[T](ctx, c, DefaultCodec, path)
+}
+
+// PostJSON кодирует body через DefaultCodec, выполняет POST и декодирует ответ, как GetJSON.
+func PostJSON[T any](ctx context.Context, c Client, path string, body any) (T, error) {
+	return PostAs[T](ctx, c, DefaultCodec, path, body)
+}
+
+// GetAs — GetJSON с явным кодеком.
+func GetAs[T any](ctx context.Context, c Client, codec Codec, path string) (T, error) {
+	resp, err := c.Get(ctx, path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer resp.Release()
+	return Decode[T](resp, codec)
+}
+
+// PostAs — PostJSON с явным кодеком.
+func PostAs[T any](ctx context.Context, c Client, codec Codec, path string, body any) (T, error) {
+	var zero T
+	b, err := codec.Marshal(body)
+	if err != nil {
+		return zero, fmt.Errorf("httpclientpool: encode request: %w", err)
+	}
+	ct := "application/json"
+	if c, ok := codec.(interface{ ContentType() string }); ok {
+		ct = c.ContentType()
+	}
+	// Do, а не Post: Post кодировал бы тело ещё раз через encoding/json.
+	resp, err := c.Do(ctx, Request{
+		Method:        http.MethodPost,
+		Path:          path,
+		Header:        http.Header{"Content-Type": {ct}},
+		Body:          bytes.NewReader(b),
+		ContentLength: int64(len(b)),
+	})
+	if err != nil {
+		return zero, err
+	}
+	defer resp.Release()
+	return Decode[T](resp, codec)
+}
+
+// Decode проверяет статус ответа и декодирует тело в T. Пустое тело 2xx-ответа
+// (например, 204) даёт нулевое значение T. Ни T, ни HTTPError не ссылаются на тело,
+// так что после Decode ответ можно освободить.
+func Decode[T any](resp Response, codec Codec) (T, error) {
+	var v T
+	if code := resp.StatusCode(); code < 200 || code > 299 {
+		body := resp.Body()
+		if len(body) > errorBodySnippet {
+			body = body[:errorBodySnippet]
+		}
+		return v, &HTTPError{
+			StatusCode: code,
+			Body:       append([]byte(nil), body...),
+			Header:     resp.Header(),
+		}
+	}
+	if len(resp.Body()) == 0 {
+		return v, nil
+	}
+	if err := codec.Unmarshal(resp.Body(), &v); err != nil {
+		return v, fmt.Errorf("httpclientpool: decode response: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,133 @@
+package pool_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+type item struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type countingCodec struct {
+	pool.JSONCodec
+	decodes atomic.Int64
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.decodes.Add(1)
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+// itemCodec — кодек не-JSON формата "id:name".
+type itemCodec struct{}
+
+func (itemCodec) ContentType() string { return "text/x-item" }
+
+func (itemCodec) Marshal(v any) ([]byte, error) {
+	it := v.(item)
+	return fmt.Appendf(nil, "%d:%s", it.ID, it.Name), nil
+}
+
+func (itemCodec) Unmarshal(data []byte, v any) error {
+	id, name, _ := strings.Cut(string(data), ":")
+	n, err := strconv.Atoi(id)
+	*v.(*item) = item{ID: n, Name: name}
+	return err
+}
+
+func testJSON(t *testing.T, newClient ClientFactory) {
+	// raw — тело и Content-Type последнего запроса к /raw.
+	var raw atomic.Pointer[[2]string]
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item":
+			_, _ = w.Write([]byte(`{"id": 7, "name": "seven"}`))
+		case "/echo":
+			if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+				http.Error(w, "bad content type "+ct, http.StatusUnsupportedMediaType)
+				return
+			}
+			var in item
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			in.ID++
+			_ = json.NewEncoder(w).Encode(in)
+		case "/raw":
+			b, _ := io.ReadAll(r.Body)
+			raw.Store(&[2]string{string(b), r.Header.Get("Content-Type")})
+			_, _ = w.Write(b)
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("X-Request-Id", "req-1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, strings.Repeat("x", 2000))
+		}
+	}))
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	got, err := pool.GetJSON[item](ctx, p, "/item")
+	if err != nil || got != (item{ID: 7, Name: "seven"}) {
+		t.Fatalf("GetJSON = %+v, %v", got, err)
+	}
+
+	got, err = pool.PostJSON[item](ctx, p, "/echo", item{ID: 1, Name: "one"})
+	if err != nil || got != (item{ID: 2, Name: "one"}) {
+		t.Fatalf("PostJSON = %+v, %v", got, err)
+	}
+
+	// Тело от кодека уходит как есть, без повторного encoding/json.
+	got, err = pool.PostAs[item](ctx, p, itemCodec{}, "/raw", item{ID: 3, Name: "three"})
+	if err != nil || got != (item{ID: 3, Name: "three"}) {
+		t.Fatalf("PostAs with a non-JSON codec = %+v, %v", got, err)
+	}
+	if r := raw.Load(); r == nil || r[0] != "3:three" || r[1] != "text/x-item" {
+		t.Fatalf("server got %q, want body %q with Content-Type text/x-item", r, "3:three")
+	}
+
+	if got, err := pool.GetJSON[*item](ctx, p, "/empty"); err != nil || got != nil {
+		t.Fatalf("want nil for 204, got %+v, %v", got, err)
+	}
+
+	_, err = pool.GetJSON[item](ctx, p, "/broken")
+	var he *pool.HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("want *pool.HTTPError, got %v", err)
+	}
+	if he.StatusCode != http.StatusServiceUnavailable || len(he.Body) != 512 || he.Header.Get("X-Request-Id") != "req-1" {
+		t.Fatalf("unexpected HTTPError: status=%d body=%d bytes header=%v", he.StatusCode, len(he.Body), he.Header)
+	}
+
+	codec := &countingCodec{}
+	if _, err := pool.GetAs[item](ctx, p, codec, "/item"); err != nil {
+		t.Fatal(err)
+	}
+	if codec.decodes.Load() != 1 {
+		t.Fatal("custom codec was not used")
+	}
+}
@@ -0,0 +1,143 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testBodyLimits(t *testing.T, newClient ClientFactory) {
+	const limit = 1 << 20
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		switch r.URL.Path {
+		case "/sized":
+			w.Header().Set("Content-Length", strconv.Itoa(n))
+			_, _ = io.WriteString(w, strings.Repeat("s", n))
+		case "/chunked":
+			for sent := 0; sent < n; sent += 64 << 10 {
+				_, _ = io.WriteString(w, strings.Repeat("c", min(64<<10, n-sent)))
+				w.(http.Flusher).Flush()
+			}
+		case "/trickle":
+			// Заголовки сразу, тело — по байту каждые 50ms.
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < n; i++ {
+				_, _ = io.WriteString(w, "t")
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		case "/slow-headers":
+			time.Sleep(600 * time.Millisecond)
+			_, _ = io.WriteString(w, "ok")
+		default:
+			_, _ = io.WriteString(w, "ok")
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+	cfg.RequestTimeout = 10 * time.Second
+	cfg.MaxResponseBodySize = limit
+	cfg.BodyReadTimeout = 300 * time.Millisecond
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	wantTooLarge := func(t *testing.T, err error) {
+		t.Helper()
+		var tl *pool.BodyTooLargeError
+		if !errors.As(err, &tl) || tl.Limit != limit || !errors.Is(err, pool.BodyTooLarge) {
+			t.Fatalf("want BodyTooLargeError{%d}, got %v (kind %s)", limit, err, pool.KindOf(err))
+		}
+	}
+
+	t.Run("Size", func(t *testing.T) {
+		resp, err := p.Get(ctx, "/sized?n="+strconv.Itoa(limit))
+		if err != nil || len(resp.Body()) != limit {
+			t.Fatalf("body of exactly the limit must pass: %v", err)
+		}
+
+		_, err = p.Get(ctx, "/sized?n="+strconv.Itoa(limit+1))
+		wantTooLarge(t, err)
+		_, err = p.Get(ctx, "/chunked?n="+strconv.Itoa(2*limit))
+		wantTooLarge(t, err)
+		_, err = p.Do(ctx, pool.Request{Path: "/chunked?n=" + strconv.Itoa(2*limit)})
+		wantTooLarge(t, err)
+
+		// Stream лимитом не ограничен.
+		sr, err := p.Stream(ctx, pool.Request{Path: "/chunked?n=" + strconv.Itoa(2*limit)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := io.Copy(io.Discard, sr.Body)
+		_ = sr.Body.Close()
+		if err != nil || n != 2*limit {
+			t.Fatalf("stream: %v after %d bytes", err, n)
+		}
+	})
+
+	t.Run("PooledBuffers", func(t *testing.T) {
+		c := cfg
+		c.PooledBuffers = true
+		pp := mustNew(t, newClient, c)
+		defer pp.Close()
+		_, err := pp.Get(ctx, "/chunked?n="+strconv.Itoa(2*limit))
+		wantTooLarge(t, err)
+		_, err = pp.Get(ctx, "/trickle?n=100")
+		if !errors.Is(err, pool.ErrBodyReadTimeout) {
+			t.Fatalf("want ErrBodyReadTimeout, got %v", err)
+		}
+	})
+
+	t.Run("BodyReadTimeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := p.Get(ctx, "/trickle?n=100")
+		if !errors.Is(err, pool.ErrBodyReadTimeout) || !errors.Is(err, pool.ReadTimeout) {
+			t.Fatalf("want ErrBodyReadTimeout, got %v (kind %s)", err, pool.KindOf(err))
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Fatalf("trickling body was cut off after %s", d)
+		}
+
+		// Ожидание заголовков в BodyReadTimeout не входит.
+		resp, err := p.Get(ctx, "/slow-headers")
+		if err != nil || string(resp.Body()) != "ok" {
+			t.Fatalf("slow headers: %v", err)
+		}
+
+		// Stream читает тело сколько нужно.
+		sr, err := p.Stream(ctx, pool.Request{Path: "/trickle?n=10"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(sr.Body)
+		_ = sr.Body.Close()
+		if err != nil || len(b) != 10 {
+			t.Fatalf("stream: %v, %d bytes", err, len(b))
+		}
+	})
+
+	if _, err := p.Get(ctx, "/ok"); err != nil {
+		t.Fatalf("pool must keep working after limit errors: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package pool
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Part — часть multipart/form-data: поле формы (Value) или файл (Filename и Body).
+type Part struct {
+	Name  string
+	Value string
+
+	Filename string
+	// ContentType файла; по умолчанию application/octet-stream.
+	ContentType string
+	Body        io.Reader
+}
+
+// Multipart собирает POST-запрос multipart/form-data, тело которого пишется потоком по мере
+// отправки: файлы читаются из Body частей, целиком в память ничего не попадает. Длина тела
+// неизвестна заранее. Ошибка чтения части обрывает запрос с этой ошибкой.
+// Запись начинается с первого чтения тела, так что неотправленный запрос ничего не держит.
+// Body частей, которые io.Closer, закрываются после записи или при закрытии тела запроса
+// (пул закрывает его всегда; неотправленный запрос закрывается через Request.CloseBody).
+func Multipart(path string, parts ...Part) Request {
+	pr, pw := io.Pipe()
+	b := &multipartBody{pr: pr, pw: pw, mw: multipart.NewWriter(pw), parts: parts}
+	return Request{
+		Method: http.MethodPost,
+		Path:   path,
+		Header: http.Header{"Content-Type": {b.mw.FormDataContentType()}},
+		Body:   b,
+	}
+}
+
+// multipartBody — тело Multipart: горутина записи стартует на первом Read.
+type multipartBody struct {
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	mw    *multipart.Writer
+	parts []Part
+	start sync.Once
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start.Do(func() {
+		go func() {
+			err := writeParts(b.mw, b.parts)
+			closeParts(b.parts)
+			b.pw.CloseWithError(err)
+		}()
+	})
+	return b.pr.Read(p)
+}
+
+// Close обрывает запись (горутина получит io.ErrClosedPipe и закроет части сама) или,
+// если она так и не началась, закрывает части сразу.
+func (b *multipartBody) Close() error {
+	b.start.Do(func() { closeParts(b.parts) })
+	return b.pr.Close()
+}
+
+func closeParts(parts []Part) {
+	for _, p := range parts {
+		if c, ok := p.Body.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
+
+func writeParts(mw *multipart.Writer, parts []Part) error {
+	for _, p := range parts {
+		if p.Body == nil {
+			if err := mw.WriteField(p.Name, p.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(p.Name), quoteEscaper.Replace(p.Filename)))
+		ct := p.ContentType
+		if ct == "" {
+			ct = DefaultBodyContentType
+		}
+		h.Set("Content-Type", ct)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, p.Body); err != nil {
+			return fmt.Errorf("multipart part %q: %w", p.Name, err)
+		}
+	}
+	return mw.Close()
+}
+
+// quoteEscaper — как в mime/multipart, который не экспортирует его.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
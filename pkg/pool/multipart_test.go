@@ -0,0 +1,63 @@
+package pool_test
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/pool"
+)
+
+type closeFlag struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (c *closeFlag) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestMultipart_NotSent(t *testing.T) {
+	before := runtime.NumGoroutine()
+	var parts []*closeFlag
+	for i := 0; i < 100; i++ {
+		part := &closeFlag{Reader: strings.NewReader("data")}
+		parts = append(parts, part)
+		_ = pool.Multipart("/form", pool.Part{Name: "f", Filename: "f", Body: part})
+	}
+	// Неотправленный запрос не держит горутину записи.
+	time.Sleep(20 * time.Millisecond)
+	if n := runtime.NumGoroutine(); n > before+5 {
+		t.Fatalf("unsent multipart requests leak goroutines: %d before, %d after", before, n)
+	}
+
+	part := &closeFlag{Reader: strings.NewReader("data")}
+	req := pool.Multipart("/form", pool.Part{Name: "f", Filename: "f", Body: part})
+	req.CloseBody()
+	if !part.closed.Load() {
+		t.Fatal("CloseBody must close part bodies of an unsent request")
+	}
+}
+
+func TestMultipart_CloseMidWrite(t *testing.T) {
+	before := runtime.NumGoroutine()
+	part := &closeFlag{Reader: strings.NewReader(strings.Repeat("x", 1<<20))}
+	req := pool.Multipart("/form", pool.Part{Name: "f", Filename: "f", Body: part})
+	if _, err := req.Body.Read(make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+	req.CloseBody()
+
+	deadline := time.Now().Add(time.Second)
+	for !part.closed.Load() || runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("writer must stop and close parts after Close (closed=%v, goroutines %d -> %d)",
+				part.closed.Load(), before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
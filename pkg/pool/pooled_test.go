@@ -0,0 +1,107 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testPooledBuffers(t *testing.T, newClient ClientFactory) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		switch r.URL.Path {
+		case "/json":
+			_, _ = io.WriteString(w, `{"n":7}`)
+		case "/echo":
+			_, _ = io.Copy(w, r.Body)
+		case "/missing":
+			http.Error(w, "nope", http.StatusNotFound)
+		default:
+			// Тело зависит от пути, чтобы переиспользованный чужой буфер был заметен.
+			_, _ = io.WriteString(w, strings.Repeat(r.URL.Path, 512))
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 4
+	cfg.PooledBuffers = true
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Bodies", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					path := fmt.Sprintf("/w%d-%d", w, i)
+					resp, err := p.Get(ctx, path)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if want := strings.Repeat(path, 512); string(resp.Body()) != want || resp.Header().Get("X-Path") != path {
+						errs <- fmt.Errorf("%s: got body of %d bytes starting %q", path, len(resp.Body()), resp.Body()[:min(16, len(resp.Body()))])
+						resp.Release()
+						return
+					}
+					resp.Release()
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("PostAndDo", func(t *testing.T) {
+		resp, err := p.Post(ctx, "/echo", map[string]int{"a": 1})
+		if err != nil || !bytes.Contains(resp.Body(), []byte(`"a":1`)) {
+			t.Fatalf("post: %v %q", err, resp.Body())
+		}
+		resp.Release()
+
+		resp, err = p.Do(ctx, pool.Request{Method: http.MethodPost, Path: "/echo", Body: strings.NewReader("raw")})
+		if err != nil || string(resp.Body()) != "raw" {
+			t.Fatalf("do: %v %q", err, resp.Body())
+		}
+		resp.Release()
+		resp.Release()
+		if resp.Body() != nil {
+			t.Fatalf("body must not be reachable after Release")
+		}
+	})
+
+	t.Run("JSONHelpers", func(t *testing.T) {
+		v, err := pool.GetJSON[struct{ N int }](ctx, p, "/json")
+		if err != nil || v.N != 7 {
+			t.Fatalf("GetJSON: %v %+v", err, v)
+		}
+		_, err = pool.GetJSON[struct{}](ctx, p, "/missing")
+		var he *pool.HTTPError
+		if !errors.As(err, &he) || he.StatusCode != 404 || !strings.Contains(string(he.Body), "nope") || he.Header.Get("X-Path") != "/missing" {
+			t.Fatalf("want HTTPError 404 with body and header, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package pool_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+// connectProxy — заглушка HTTP-прокси: принимает CONNECT с Basic-авторизацией user:pass
+// и туннелирует любой хост на upstream. Запоминает запрошенные хосты.
+type connectProxy struct {
+	l        net.Listener
+	upstream string
+
+	mu      sync.Mutex
+	targets []string
+}
+
+func newConnectProxy(t *testing.T, upstream string) *connectProxy {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &connectProxy{l: l, upstream: upstream}
+	t.Cleanup(func() { l.Close() })
+	go p.serve()
+	return p
+}
+
+func (p *connectProxy) URL(userinfo string) string {
+	if userinfo != "" {
+		userinfo += "@"
+	}
+	return "http://" + userinfo + p.l.Addr().String()
+}
+
+func (p *connectProxy) Targets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func (p *connectProxy) serve() {
+	for {
+		c, err := p.l.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(c)
+	}
+}
+
+func (p *connectProxy) handle(c net.Conn) {
+	defer c.Close()
+	br := bufio.NewReader(c)
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return
+	}
+	if req.Method != http.MethodConnect {
+		_, _ = io.WriteString(c, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+		return
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	if req.Header.Get("Proxy-Authorization") != want {
+		_, _ = io.WriteString(c, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+	p.mu.Lock()
+	p.targets = append(p.targets, req.Host)
+	p.mu.Unlock()
+
+	up, err := net.Dial("tcp", p.upstream)
+	if err != nil {
+		_, _ = io.WriteString(c, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer up.Close()
+	_, _ = io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(up, br); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(c, up); done <- struct{}{} }()
+	<-done
+}
+
+func testProxy(t *testing.T, newClient ClientFactory) {
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("via " + r.Host))
+	}))
+	defer srv.Close()
+	upstream := srv.Listener.Addr().String()
+	_, port, _ := net.SplitHostPort(upstream)
+	proxy := newConnectProxy(t, upstream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Хост не loopback: запросы на localhost прокси не проксируются.
+	target := "upstream.test:" + port
+	cfg := config.TestConfig()
+	cfg.BaseURL = "https://" + target
+	cfg.Size = 2
+	cfg.Proxy.URL = proxy.URL("user:pass")
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+	resp, err := p.Get(ctx, "/")
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	if got := string(resp.Body()); got != "via "+target {
+		t.Fatalf("unexpected body %q", got)
+	}
+	if got := proxy.Targets(); len(got) != 1 || got[0] != target {
+		t.Fatalf("want one CONNECT to %s, got %v", target, got)
+	}
+
+	// Неверные учётные данные.
+	cfg.Proxy.URL = proxy.URL("user:wrong")
+	bad := mustNew(t, newClient, cfg)
+	defer bad.Close()
+	if _, err := bad.Get(ctx, "/"); err == nil {
+		t.Fatal("want error with wrong proxy credentials")
+	}
+
+	// Из окружения.
+	t.Setenv("HTTPS_PROXY", proxy.URL("user:pass"))
+	t.Setenv("HTTP_PROXY", proxy.URL("user:pass"))
+	t.Setenv("NO_PROXY", "")
+	cfg.Proxy = config.Proxy{FromEnv: true}
+	env := mustNew(t, newClient, cfg)
+	defer env.Close()
+	if _, err := env.Get(ctx, "/"); err != nil {
+		t.Fatalf("GET through proxy from env: %v", err)
+	}
+	if got := len(proxy.Targets()); got != 2 {
+		t.Fatalf("want env proxy to be used, got %d CONNECTs", got)
+	}
+
+	// NoProxy: хост ходит напрямую (адрес подставляет DNS-режим с фейковым резолвером).
+	res := &fakeResolver{}
+	res.set("direct.test", "127.0.0.1")
+	cfg.BaseURL = "https://direct.test:" + port
+	cfg.Proxy = config.Proxy{URL: proxy.URL("user:pass"), NoProxy: []string{"direct.test"}}
+	cfg.DNSRefresh = time.Minute
+	cfg.Resolver = res
+	direct := mustNew(t, newClient, cfg)
+	defer direct.Close()
+	resp, err = direct.Get(ctx, "/")
+	if err != nil {
+		t.Fatalf("GET bypassing proxy: %v", err)
+	}
+	if !strings.HasPrefix(string(resp.Body()), "via direct.test") {
+		t.Fatalf("unexpected body %q", resp.Body())
+	}
+	if got := proxy.Targets(); len(got) != 2 {
+		t.Fatalf("NoProxy host must not go through proxy, got CONNECTs %v", got)
+	}
+}
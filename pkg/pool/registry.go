@@ -0,0 +1,81 @@
+package pool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+type MemberSnapshot struct {
+	Index    int       `json:"index"`
+	Endpoint string    `json:"endpoint"`
+	Addr     string    `json:"addr,omitempty"`
+	InFlight int64     `json:"in_flight"`
+	Requests uint64    `json:"requests"`
+	Failures uint64    `json:"failures"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+type EndpointSnapshot struct {
+	URL                 string    `json:"url"`
+	Addr                string    `json:"addr,omitempty"`
+	Weight              int       `json:"weight"`
+	Members             int       `json:"members"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	DownUntil           time.Time `json:"down_until"`
+}
+
+type Snapshot struct {
+	Name         string             `json:"name"`
+	Backend      string             `json:"backend"`
+	Config       config.Config      `json:"config"`
+	Endpoints    []EndpointSnapshot `json:"endpoints"`
+	Members      []MemberSnapshot   `json:"members"`
+	RecentErrors []ErrorRecord      `json:"recent_errors"`
+}
+
+// Inspector — пул, который умеет отдать своё текущее состояние.
+type Inspector interface {
+	Snapshot() Snapshot
+}
+
+var registry = struct {
+	sync.RWMutex
+	pools map[string]Inspector
+}{pools: map[string]Inspector{}}
+
+// Register добавляет пул в общий реестр процесса. Пул с тем же именем заменяется.
+func Register(name string, p Inspector) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.pools[name] = p
+}
+
+// Unregister удаляет пул из реестра, только если под именем зарегистрирован именно он.
+func Unregister(name string, p Inspector) {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.pools[name] == p {
+		delete(registry.pools, name)
+	}
+}
+
+// Snapshots возвращает состояние всех зарегистрированных пулов, отсортированное по имени.
+func Snapshots() []Snapshot {
+	registry.RLock()
+	ps := make([]Inspector, 0, len(registry.pools))
+	for _, p := range registry.pools {
+		ps = append(ps, p)
+	}
+	registry.RUnlock()
+
+	out := make([]Snapshot, 0, len(ps))
+	for _, p := range ps {
+		out = append(out, p.Snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,29 @@
+package pool
+
+import (
+	"io"
+	"net/http"
+)
+
+// Request — запрос для Client.Do и Client.Stream. Method по умолчанию — GET.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	// Body читается потоком по мере отправки и не буферизуется. Если Body — io.Closer,
+	// пул закрывает его после запроса, в том числе при ошибке.
+	Body io.Reader
+	// ContentLength — длина Body, если известна. 0 при непустом Body — длина
+	// неизвестна, тело уходит chunked (в h2 — просто потоком DATA-фреймов).
+	ContentLength int64
+}
+
+// DefaultBodyContentType — Content-Type тела, для которого он не задан в Header.
+const DefaultBodyContentType = "application/octet-stream"
+
+// CloseBody закрывает Body, если запрос так и не был отправлен (например, Acquire вернул ошибку).
+func (r Request) CloseBody() {
+	if c, ok := r.Body.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,83 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testResize(t *testing.T, newClient ClientFactory) {
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+	r, ok := p.(pool.Resizer)
+	if !ok {
+		t.Fatal("pool does not implement pool.Resizer")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Запросы идут всё время, пока пул растёт и сжимается: ни один не должен упасть.
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := p.Get(ctx, "/"); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					return
+				}
+			}
+		}()
+	}
+
+	for _, n := range []int{8, 1, 16, 3, 4} {
+		if err := r.Resize(n); err != nil {
+			t.Fatalf("Resize(%d): %v", n, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+	select {
+	case err := <-errs:
+		t.Fatalf("request failed during resize: %v", err)
+	default:
+	}
+
+	if got := len(p.(pool.Inspector).Snapshot().Members); got != 4 {
+		t.Fatalf("want 4 members after resize, got %d", got)
+	}
+
+	p.Close()
+	if err := r.Resize(2); !errors.Is(err, pool.ErrClosed) {
+		t.Fatalf("want ErrClosed after Close, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+func testShutdown(t *testing.T, newClient ClientFactory) {
+	var started atomic.Int64
+	release := make(chan struct{})
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started.Add(1)
+			<-release
+		}
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer srv.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	// Свободные члены нужны, чтобы пробные запросы до начала Shutdown не вставали в очередь за медленными.
+	cfg.Size = 16
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	const inFlight = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, inFlight)
+	for range inFlight {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := p.Get(ctx, "/slow")
+			if err == nil && string(resp.Body()) != "done" {
+				err = errors.New("unexpected body " + string(resp.Body()))
+			}
+			errs <- err
+		}()
+	}
+	waitFor(t, ctx, func() bool { return started.Load() == inFlight }, "requests to reach the server")
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- p.(pool.Shutdowner).Shutdown(ctx) }()
+
+	waitFor(t, ctx, func() bool {
+		_, err := p.Get(ctx, "/fast")
+		return errors.Is(err, pool.ErrClosed)
+	}, "new requests to be rejected with ErrClosed")
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned before in-flight requests finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("in-flight request failed during shutdown: %v", err)
+		}
+	}
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if started.Load() != inFlight {
+		t.Fatalf("rejected requests must not reach the server, got %d", started.Load())
+	}
+}
+
+func testShutdownDeadline(t *testing.T, newClient ClientFactory) {
+	release := make(chan struct{})
+	var started atomic.Int64
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started.Add(1)
+		<-release
+	}))
+	defer srv.Close()
+	// До srv.Close: он ждёт завершения обработчиков.
+	defer close(release)
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.RequestTimeout = 2 * time.Second
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _, _ = p.Get(ctx, "/hang") }()
+	waitFor(t, ctx, func() bool { return started.Load() == 1 }, "request to reach the server")
+
+	sctx, scancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer scancel()
+	if err := p.(pool.Shutdowner).Shutdown(sctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded from Shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package pool_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+)
+
+// socksProxy — заглушка SOCKS5 (RFC 1928) с авторизацией user:pass (RFC 1929): принимает
+// CONNECT к любому хосту и туннелирует его на upstream. Запоминает запрошенные хосты.
+type socksProxy struct {
+	l        net.Listener
+	upstream string
+
+	mu      sync.Mutex
+	targets []string
+}
+
+func newSocksProxy(t *testing.T, upstream string) *socksProxy {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &socksProxy{l: l, upstream: upstream}
+	t.Cleanup(func() { l.Close() })
+	go p.serve()
+	return p
+}
+
+func (p *socksProxy) URL(scheme, userinfo string) string {
+	return scheme + "://" + userinfo + "@" + p.l.Addr().String()
+}
+
+func (p *socksProxy) Targets() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func (p *socksProxy) serve() {
+	for {
+		c, err := p.l.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(c)
+	}
+}
+
+func (p *socksProxy) handle(c net.Conn) {
+	defer c.Close()
+	// Приветствие: версия и методы; нужен 0x02 (логин и пароль).
+	var hdr [2]byte
+	if _, err := io.ReadFull(c, hdr[:]); err != nil || hdr[0] != 5 {
+		return
+	}
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(c, methods); err != nil {
+		return
+	}
+	_, _ = c.Write([]byte{5, 2})
+
+	// RFC 1929: версия 1, логин, пароль.
+	read := func() (string, bool) {
+		var n [1]byte
+		if _, err := io.ReadFull(c, n[:]); err != nil {
+			return "", false
+		}
+		b := make([]byte, n[0])
+		_, err := io.ReadFull(c, b)
+		return string(b), err == nil
+	}
+	var ver [1]byte
+	if _, err := io.ReadFull(c, ver[:]); err != nil {
+		return
+	}
+	user, ok1 := read()
+	pass, ok2 := read()
+	if !ok1 || !ok2 || user != "user" || pass != "pass" {
+		_, _ = c.Write([]byte{1, 1})
+		return
+	}
+	_, _ = c.Write([]byte{1, 0})
+
+	// Запрос: VER CMD RSV ATYP ADDR PORT; поддерживается только CONNECT.
+	var req [4]byte
+	if _, err := io.ReadFull(c, req[:]); err != nil || req[1] != 1 {
+		return
+	}
+	var host string
+	switch req[3] {
+	case 1, 4:
+		ip := make(net.IP, 4)
+		if req[3] == 4 {
+			ip = make(net.IP, 16)
+		}
+		if _, err := io.ReadFull(c, ip); err != nil {
+			return
+		}
+		host = ip.String()
+	case 3:
+		var ok bool
+		if host, ok = read(); !ok {
+			return
+		}
+	default:
+		return
+	}
+	var port [2]byte
+	if _, err := io.ReadFull(c, port[:]); err != nil {
+		return
+	}
+	p.mu.Lock()
+	p.targets = append(p.targets, net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))))
+	p.mu.Unlock()
+
+	up, err := net.Dial("tcp", p.upstream)
+	if err != nil {
+		_, _ = c.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer up.Close()
+	_, _ = c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(up, c); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(c, up); done <- struct{}{} }()
+	<-done
+}
+
+func testSocksProxy(t *testing.T, newClient ClientFactory) {
+	srv := newTLSServerWithHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("via " + r.Host))
+	}))
+	defer srv.Close()
+	upstream := srv.Listener.Addr().String()
+	_, port, _ := net.SplitHostPort(upstream)
+	proxy := newSocksProxy(t, upstream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Хост не резолвится локально: его имя должно уйти прокси как есть.
+	target := "upstream.test:" + port
+	cfg := config.TestConfig()
+	cfg.BaseURL = "https://" + target
+	cfg.Size = 2
+
+	for _, scheme := range []string{"socks5", "socks5h"} {
+		t.Run(scheme, func(t *testing.T) {
+			before := len(proxy.Targets())
+			cfg.Proxy.URL = proxy.URL(scheme, "user:pass")
+			p := mustNew(t, newClient, cfg)
+			defer p.Close()
+			resp, err := p.Get(ctx, "/")
+			if err != nil {
+				t.Fatalf("GET through SOCKS5 proxy: %v", err)
+			}
+			if got := string(resp.Body()); got != "via "+target {
+				t.Fatalf("unexpected body %q", got)
+			}
+			if got := proxy.Targets()[before:]; len(got) != 1 || got[0] != target {
+				t.Fatalf("want one CONNECT to %s, got %v", target, got)
+			}
+
+			cfg.Proxy.URL = proxy.URL(scheme, "user:wrong")
+			bad := mustNew(t, newClient, cfg)
+			defer bad.Close()
+			if _, err := bad.Get(ctx, "/"); err == nil {
+				t.Fatal("want error with wrong proxy credentials")
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const recentErrorsCap = 32
+
+// MemberStats — счётчики одного члена пула (одного клиента/соединения).
+type MemberStats struct {
+	inFlight atomic.Int64
+	requests atomic.Uint64
+	failures atomic.Uint64
+	lastUsed atomic.Int64
+}
+
+func (s *MemberStats) Begin() {
+	s.inFlight.Add(1)
+	s.requests.Add(1)
+	s.lastUsed.Store(time.Now().UnixNano())
+}
+
+// Abort отменяет Begin для запроса, который так и не был отправлен.
+func (s *MemberStats) Abort() {
+	s.inFlight.Add(-1)
+	s.requests.Add(^uint64(0))
+}
+
+func (s *MemberStats) End(err error) {
+	s.inFlight.Add(-1)
+	if err != nil {
+		s.failures.Add(1)
+	}
+}
+
+func (s *MemberStats) InFlight() int64 { return s.inFlight.Load() }
+
+func (s *MemberStats) Snapshot(index int, endpoint, addr string) MemberSnapshot {
+	ms := MemberSnapshot{
+		Index:    index,
+		Endpoint: endpoint,
+		Addr:     addr,
+		InFlight: s.inFlight.Load(),
+		Requests: s.requests.Load(),
+		Failures: s.failures.Load(),
+	}
+	if ns := s.lastUsed.Load(); ns != 0 {
+		ms.LastUsed = time.Unix(0, ns)
+	}
+	return ms
+}
+
+type ErrorRecord struct {
+	Time   time.Time `json:"time"`
+	Member int       `json:"member"`
+	Path   string    `json:"path"`
+	Error  string    `json:"error"`
+}
+
+// ErrorLog — кольцевой буфер последних ошибок пула. Нулевое значение готово к работе.
+type ErrorLog struct {
+	mu   sync.Mutex
+	buf  []ErrorRecord
+	next int
+}
+
+func (l *ErrorLog) Add(member int, path string, err error) {
+	rec := ErrorRecord{Time: time.Now(), Member: member, Path: path, Error: err.Error()}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buf) < recentErrorsCap {
+		l.buf = append(l.buf, rec)
+		return
+	}
+	l.buf[l.next] = rec
+	l.next = (l.next + 1) % recentErrorsCap
+}
+
+// Recent возвращает ошибки от новых к старым.
+func (l *ErrorLog) Recent() []ErrorRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]ErrorRecord, 0, len(l.buf))
+	for i := len(l.buf) - 1; i >= 0; i-- {
+		out = append(out, l.buf[(l.next+i)%len(l.buf)])
+	}
+	return out
+}
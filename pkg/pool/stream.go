@@ -0,0 +1,14 @@
+package pool
+
+import (
+	"io"
+	"net/http"
+)
+
+// StreamResponse — ответ, тело которого читается из соединения по мере чтения Body.
+// Член пула остаётся занятым, пока Body не закрыт, поэтому Body нужно закрывать всегда.
+type StreamResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
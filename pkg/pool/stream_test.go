@@ -0,0 +1,154 @@
+package pool_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+const streamLargeSize = 32 << 20
+
+func testStream(t *testing.T, newClient ClientFactory) {
+	next := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ndjson":
+			// Вторая строка уходит только после того, как клиент прочитал первую.
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = io.WriteString(w, `{"n":1}`+"\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-next:
+			case <-r.Context().Done():
+				return
+			}
+			_, _ = io.WriteString(w, `{"n":2}`+"\n")
+		case "/large":
+			w.Header().Set("Content-Length", strconv.Itoa(streamLargeSize))
+			chunk := bytes.Repeat([]byte("A"), 64<<10)
+			for n := 0; n < streamLargeSize; n += len(chunk) {
+				if _, err := w.Write(chunk); err != nil {
+					return
+				}
+			}
+		case "/echo-header":
+			w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+			_, _ = io.WriteString(w, r.Method)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 1
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	inFlight := func() int64 { return p.(pool.Inspector).Snapshot().Members[0].InFlight }
+
+	t.Run("Incremental", func(t *testing.T) {
+		resp, err := p.Stream(ctx, pool.Request{Path: "/ndjson"})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("status=%d header=%v", resp.StatusCode, resp.Header)
+		}
+		br := bufio.NewReader(resp.Body)
+		line, err := br.ReadString('\n')
+		if err != nil || line != `{"n":1}`+"\n" {
+			t.Fatalf("first line %q, err %v", line, err)
+		}
+		if n := inFlight(); n != 1 {
+			t.Fatalf("member must stay reserved while body is open, in flight %d", n)
+		}
+		close(next)
+		rest, err := io.ReadAll(br)
+		if err != nil || string(rest) != `{"n":2}`+"\n" {
+			t.Fatalf("rest %q, err %v", rest, err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+		if n := inFlight(); n != 0 {
+			t.Fatalf("member must be released on Close, in flight %d", n)
+		}
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		resp, err := p.Stream(ctx, pool.Request{Path: "/large"})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		defer resp.Body.Close()
+		n, err := io.Copy(io.Discard, resp.Body)
+		if err != nil || n != streamLargeSize {
+			t.Fatalf("read %d bytes, err %v", n, err)
+		}
+	})
+
+	t.Run("MethodAndHeader", func(t *testing.T) {
+		resp, err := p.Stream(ctx, pool.Request{
+			Method: http.MethodPut,
+			Path:   "/echo-header",
+			Header: http.Header{"X-Test": {"yes"}},
+		})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != http.MethodPut || resp.Header.Get("X-Echo") != "yes" {
+			t.Fatalf("body %q, X-Echo %q", body, resp.Header.Get("X-Echo"))
+		}
+	})
+
+	t.Run("CloseEarly", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			resp, err := p.Stream(ctx, pool.Request{Path: "/large"})
+			if err != nil {
+				t.Fatalf("stream %d: %v", i, err)
+			}
+			if _, err := io.CopyN(io.Discard, resp.Body, 1024); err != nil {
+				t.Fatalf("read %d: %v", i, err)
+			}
+			resp.Body.Close()
+		}
+		if n := inFlight(); n != 0 {
+			t.Fatalf("in flight %d after closing bodies", n)
+		}
+		resp, err := p.Get(ctx, "/echo-header")
+		if err != nil || resp.StatusCode() != 200 {
+			t.Fatalf("get after early close: %v", err)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		q := config.TestConfig()
+		q.BaseURL = deadURL(t)
+		dead := mustNew(t, newClient, q)
+		defer dead.Close()
+		_, err := dead.Stream(ctx, pool.Request{Path: "/"})
+		if k := pool.KindOf(err); k != pool.DialFailed {
+			t.Fatalf("want DialFailed, got %s: %v", k, err)
+		}
+		if n := dead.(pool.Inspector).Snapshot().Members[0].InFlight; n != 0 {
+			t.Fatalf("member not released after error, in flight %d", n)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/tlsconf"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выпускает лист, подписанный CA, и возвращает его PEM (cert, key).
+func (ca *testCA) issue(t *testing.T, cn string, dns []string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     dns,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kb, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb})
+}
+
+// newMTLSServer поднимает TLS-сервер с сертификатом для serverName, требующий клиентский сертификат от ca.
+// В ответ пишет CN клиентского сертификата.
+func newMTLSServer(t *testing.T, ca *testCA, serverName string) *httptest.Server {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, serverName, []string{serverName}, x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	return srv
+}
+
+func writeKeyPair(t *testing.T, dir string, certPEM, keyPEM []byte) (certFile, keyFile string) {
+	t.Helper()
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func testMutualTLS(t *testing.T, newClient ClientFactory) {
+	ca := newTestCA(t)
+	srv := newMTLSServer(t, ca, "pool.test")
+	defer srv.Close()
+
+	certPEM, keyPEM := ca.issue(t, "client-a", nil, x509.ExtKeyUsageClientAuth)
+	certFile, keyFile := writeKeyPair(t, t.TempDir(), certPEM, keyPEM)
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+	cfg.TLS = config.TLS{
+		RootCAPEM:    string(ca.pem),
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ServerName:   "pool.test",
+		MinVersion:   "1.2",
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	}
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := p.Get(ctx, "/whoami")
+	if err != nil {
+		t.Fatalf("mTLS GET: %v", err)
+	}
+	if string(resp.Body()) != "client-a" {
+		t.Fatalf("server saw client %q", resp.Body())
+	}
+
+	noCert := cfg
+	noCert.TLS.CertFile, noCert.TLS.KeyFile = "", ""
+	p2 := mustNew(t, newClient, noCert)
+	defer p2.Close()
+	if _, err := p2.Get(ctx, "/whoami"); err == nil {
+		t.Fatalf("expected handshake failure without client certificate")
+	}
+}
+
+func testClientCertReload(t *testing.T, newClient ClientFactory) {
+	ca := newTestCA(t)
+	srv := newMTLSServer(t, ca, "pool.test")
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, "client-a", nil, x509.ExtKeyUsageClientAuth)
+	certFile, keyFile := writeKeyPair(t, dir, certPEM, keyPEM)
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 1
+	cfg.TLS = config.TLS{RootCAPEM: string(ca.pem), CertFile: certFile, KeyFile: keyFile, ServerName: "pool.test"}
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if resp, err := p.Get(ctx, "/"); err != nil || string(resp.Body()) != "client-a" {
+		t.Fatalf("first GET: err=%v", err)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "client-b", nil, x509.ExtKeyUsageClientAuth)
+	writeKeyPair(t, dir, certPEM, keyPEM)
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certFile, future, future)
+	_ = os.Chtimes(keyFile, future, future)
+
+	srv.CloseClientConnections()
+
+	var got string
+	for i := 0; i < 5 && got != "client-b"; i++ {
+		if resp, err := p.Get(ctx, "/"); err == nil {
+			got = string(resp.Body())
+		}
+	}
+	if got != "client-b" {
+		t.Fatalf("client certificate was not reloaded, server saw %q", got)
+	}
+}
+
+func testPinning(t *testing.T, newClient ClientFactory) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 1
+	cfg.TLS.RootCAPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	const wrongPin = "sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+	t.Run("match", func(t *testing.T) {
+		c := cfg
+		c.TLS.Pins = []string{wrongPin, tlsconf.SPKIPin(srv.Certificate())}
+		p := mustNew(t, newClient, c)
+		defer p.Close()
+		if _, err := p.Get(ctx, "/"); err != nil {
+			t.Fatalf("pinned GET: %v", err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		c := cfg
+		c.TLS.Pins = []string{wrongPin}
+		p := mustNew(t, newClient, c)
+		defer p.Close()
+		_, err := p.Get(ctx, "/")
+		var pe *tlsconf.PinError
+		if !errors.As(err, &pe) {
+			t.Fatalf("want *tlsconf.PinError, got %v", err)
+		}
+		if len(pe.Got) == 0 || pe.Got[0] != tlsconf.SPKIPin(srv.Certificate()) {
+			t.Fatalf("unexpected pins in error: %v", pe.Got)
+		}
+	})
+
+	t.Run("report-only", func(t *testing.T) {
+		var buf bytes.Buffer
+		c := cfg
+		c.TLS.Pins = []string{wrongPin}
+		c.TLS.PinReportOnly = true
+		c.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+		p := mustNew(t, newClient, c)
+		defer p.Close()
+		if _, err := p.Get(ctx, "/"); err != nil {
+			t.Fatalf("report-only GET: %v", err)
+		}
+		if !strings.Contains(buf.String(), "pin mismatch") {
+			t.Fatalf("expected mismatch to be logged, got %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,236 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+const (
+	uploadLargeSize = 300 << 20
+	uploadSmallSize = 16 << 20
+)
+
+var genBlock = bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 2048)
+
+// genReader отдаёт size байт, не держа их в памяти, и считает их CRC. Раз в 16 МБ
+// он замеряет кучу: буферизующий клиент упёрся бы в размер тела.
+type genReader struct {
+	left     int64
+	off      int
+	sent     int64
+	crc      hash.Hash32
+	peakHeap uint64
+	closed   atomic.Bool
+}
+
+func newGen(size int64) *genReader {
+	return &genReader{left: size, crc: crc32.NewIEEE()}
+}
+
+func (g *genReader) Read(p []byte) (int, error) {
+	if g.left == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, genBlock[g.off:])
+	if int64(n) > g.left {
+		n = int(g.left)
+	}
+	g.off = (g.off + n) % len(genBlock)
+	g.left -= int64(n)
+	_, _ = g.crc.Write(p[:n])
+	if g.sent/(16<<20) != (g.sent+int64(n))/(16<<20) {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		g.peakHeap = max(g.peakHeap, ms.HeapAlloc)
+	}
+	g.sent += int64(n)
+	return n, nil
+}
+
+func (g *genReader) Close() error {
+	g.closed.Store(true)
+	return nil
+}
+
+func (g *genReader) sum() string { return fmt.Sprintf("%d %08x", g.sent, g.crc.Sum32()) }
+
+// countBody — сервер читает тело потоком и отвечает его длиной и CRC.
+func countBody(r io.Reader) string {
+	h := crc32.NewIEEE()
+	n, _ := io.Copy(h, r)
+	return fmt.Sprintf("%d %08x", n, h.Sum32())
+}
+
+func testUpload(t *testing.T, newClient ClientFactory) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			cl := "unknown"
+			if r.ContentLength >= 0 {
+				cl = fmt.Sprint(r.ContentLength)
+			}
+			w.Header().Set("X-Content-Length", cl)
+			w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+			_, _ = io.WriteString(w, countBody(r.Body))
+		case "/form":
+			mr, err := r.MultipartReader()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			var out []string
+			for {
+				part, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if part.FileName() == "" {
+					v, _ := io.ReadAll(part)
+					out = append(out, part.FormName()+"="+string(v))
+					continue
+				}
+				out = append(out, fmt.Sprintf("%s:%s:%s=%s", part.FormName(), part.FileName(),
+					part.Header.Get("Content-Type"), countBody(part)))
+			}
+			_, _ = io.WriteString(w, strings.Join(out, "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := newTLSServerWithHandler(h)
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 2
+	cfg.RequestTimeout = 2 * time.Minute
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	upload := func(t *testing.T, gen *genReader, length int64) pool.Response {
+		t.Helper()
+		resp, err := p.Do(ctx, pool.Request{Method: http.MethodPut, Path: "/upload", Body: gen, ContentLength: length})
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		if got, want := string(resp.Body()), gen.sum(); resp.StatusCode() != 200 || got != want {
+			t.Fatalf("status %d, server got %q, sent %q", resp.StatusCode(), got, want)
+		}
+		if !gen.closed.Load() {
+			t.Fatalf("request body was not closed")
+		}
+		return resp
+	}
+
+	t.Run("LargeUnknownLength", func(t *testing.T) {
+		gen := newGen(uploadLargeSize)
+		resp := upload(t, gen, 0)
+		if cl := resp.Header().Get("X-Content-Length"); cl != "unknown" {
+			t.Fatalf("want chunked body, server saw Content-Length %s", cl)
+		}
+		if ct := resp.Header().Get("X-Content-Type"); ct != pool.DefaultBodyContentType {
+			t.Fatalf("want default Content-Type, got %q", ct)
+		}
+		if gen.peakHeap > 128<<20 {
+			t.Fatalf("body looks buffered: heap peaked at %d MB", gen.peakHeap>>20)
+		}
+	})
+
+	t.Run("KnownLength", func(t *testing.T) {
+		gen := newGen(uploadSmallSize)
+		resp := upload(t, gen, uploadSmallSize)
+		if cl := resp.Header().Get("X-Content-Length"); cl != fmt.Sprint(uploadSmallSize) {
+			t.Fatalf("want Content-Length %d, server saw %s", uploadSmallSize, cl)
+		}
+	})
+
+	t.Run("StreamWithBody", func(t *testing.T) {
+		gen := newGen(uploadSmallSize)
+		resp, err := p.Stream(ctx, pool.Request{Method: http.MethodPost, Path: "/upload", Body: gen})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(got) != gen.sum() {
+			t.Fatalf("server got %q, sent %q", got, gen.sum())
+		}
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		big, small := newGen(64<<20), newGen(1<<10)
+		resp, err := p.Do(ctx, pool.Multipart("/form",
+			pool.Part{Name: "title", Value: "report"},
+			pool.Part{Name: "data", Filename: "data.bin", Body: big},
+			pool.Part{Name: "note", Filename: `a "b".txt`, ContentType: "text/plain", Body: small},
+		))
+		if err != nil {
+			t.Fatalf("multipart: %v", err)
+		}
+		want := strings.Join([]string{
+			"title=report",
+			"data:data.bin:application/octet-stream=" + big.sum(),
+			`note:a "b".txt:text/plain=` + small.sum(),
+		}, "\n")
+		if resp.StatusCode() != 200 || string(resp.Body()) != want {
+			t.Fatalf("status %d, server saw:\n%s\nwant:\n%s", resp.StatusCode(), resp.Body(), want)
+		}
+		if !big.closed.Load() || !small.closed.Load() {
+			t.Fatalf("part bodies must be closed after the request")
+		}
+	})
+
+	t.Run("MultipartPartError", func(t *testing.T) {
+		boom := io.MultiReader(strings.NewReader("partial"), iotestErrReader{})
+		_, err := p.Do(ctx, pool.Multipart("/form", pool.Part{Name: "f", Filename: "f", Body: boom}))
+		if err == nil || !strings.Contains(err.Error(), "generator failed") {
+			t.Fatalf("want part read error, got %v", err)
+		}
+	})
+
+	t.Run("ClosedPoolClosesBody", func(t *testing.T) {
+		q := mustNew(t, newClient, cfg)
+		q.Close()
+		gen := newGen(1)
+		if _, err := q.Do(ctx, pool.Request{Method: http.MethodPost, Path: "/upload", Body: gen}); !errors.Is(err, pool.PoolClosed) {
+			t.Fatalf("want PoolClosed, got %v", err)
+		}
+		if !gen.closed.Load() {
+			t.Fatalf("body of a request that was never sent must be closed")
+		}
+
+		part := newGen(1)
+		if _, err := q.Do(ctx, pool.Multipart("/form", pool.Part{Name: "f", Filename: "f", Body: part})); !errors.Is(err, pool.PoolClosed) {
+			t.Fatalf("want PoolClosed, got %v", err)
+		}
+		if !part.closed.Load() {
+			t.Fatalf("part body of a multipart request that was never sent must be closed")
+		}
+	})
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) { return 0, errors.New("generator failed") }
@@ -0,0 +1,45 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Warmer — пул, который умеет заранее установить соединения всех своих членов.
+type Warmer interface {
+	Warmup(ctx context.Context) (WarmupReport, error)
+}
+
+// WarmupReport — итог прогрева.
+type WarmupReport struct {
+	Ready  int
+	Failed []WarmupFailure
+	// Pending — члены, прогрев которых ещё идёт (New возвращается, набрав MinReady).
+	Pending int
+}
+
+type WarmupFailure struct {
+	Member   int
+	Endpoint string
+	Addr     string
+	Err      error
+}
+
+func (f WarmupFailure) Error() string {
+	if f.Addr != "" {
+		return fmt.Sprintf("member %d (%s via %s): %v", f.Member, f.Endpoint, f.Addr, f.Err)
+	}
+	return fmt.Sprintf("member %d (%s): %v", f.Member, f.Endpoint, f.Err)
+}
+
+func (f WarmupFailure) Unwrap() error { return f.Err }
+
+// Err объединяет ошибки неудачных членов; nil, если таких нет.
+func (r WarmupReport) Err() error {
+	errs := make([]error, len(r.Failed))
+	for i, f := range r.Failed {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package pool_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+// deadURL — адрес, на котором никто не слушает.
+func deadURL(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "https://" + addr
+}
+
+func testWarmup(t *testing.T, newClient ClientFactory) {
+	var conns, readyHits atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" {
+			readyHits.Add(1)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	srv.EnableHTTP2 = true
+	srv.Config.ConnState = func(_ net.Conn, st http.ConnState) {
+		if st == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 4
+	cfg.Warmup = config.Warmup{Enabled: true, Path: "/ready", Timeout: 5 * time.Second}
+
+	p := mustNew(t, newClient, cfg)
+	defer p.Close()
+	if conns.Load() != 4 || readyHits.Load() != 4 {
+		t.Fatalf("want 4 connections and 4 warmup hits after New, got %d and %d", conns.Load(), readyHits.Load())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 8; i++ {
+		if _, err := p.Get(ctx, "/"); err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+	}
+	if conns.Load() != 4 {
+		t.Fatalf("requests after warmup must reuse connections, got %d connections", conns.Load())
+	}
+
+	// Половина членов смотрит в мёртвый эндпоинт.
+	dead := deadURL(t)
+	cfg = config.TestConfig()
+	cfg.Size = 4
+	cfg.Endpoints = []config.Endpoint{{URL: srv.URL}, {URL: dead}}
+	cfg.FailoverThreshold = 0
+	cfg.Warmup = config.Warmup{Enabled: true, Timeout: 5 * time.Second}
+	if _, err := newClient(cfg); err == nil || !strings.Contains(err.Error(), dead[len("https://"):]) {
+		t.Fatalf("want New to fail naming the dead endpoint when all members are required, got %v", err)
+	}
+
+	cfg.Warmup.MinReady = 2
+	p2 := mustNew(t, newClient, cfg)
+	defer p2.Close()
+
+	rep, err := p2.(pool.Warmer).Warmup(ctx)
+	if err == nil {
+		t.Fatal("want warmup error for dead endpoint")
+	}
+	if rep.Ready != 2 || len(rep.Failed) != 2 || rep.Pending != 0 {
+		t.Fatalf("want 2 ready and 2 failed, got %+v", rep)
+	}
+	for _, f := range rep.Failed {
+		if f.Endpoint != dead {
+			t.Fatalf("failed member %d points at %s, want %s", f.Member, f.Endpoint, dead)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Package proxyconf — выбор исходящего прокси по cfg.Proxy, общий для обоих бэкендов.
+package proxyconf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"httpclientpool/pkg/config"
+)
+
+// Func возвращает прокси для URL запроса или nil, если идти напрямую.
+type Func func(*url.URL) (*url.URL, error)
+
+// New собирает Func из cfg.Proxy. Если прокси не настроен, возвращает nil.
+// Как и net/http, запросы на localhost и loopback-адреса никогда не проксируются.
+func New(cfg config.Config) (Func, error) {
+	p := cfg.Proxy
+	var pc *httpproxy.Config
+	switch {
+	case p.FromEnv:
+		pc = httpproxy.FromEnvironment()
+	case p.URL != "":
+		u, err := url.Parse(p.URL)
+		if err != nil {
+			return nil, fmt.Errorf("proxy: %w", err)
+		}
+		switch u.Scheme {
+		case "http", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("proxy: unsupported scheme %q", u.Scheme)
+		}
+		pc = &httpproxy.Config{HTTPProxy: p.URL, HTTPSProxy: p.URL, NoProxy: strings.Join(p.NoProxy, ",")}
+	default:
+		return nil, nil
+	}
+	return Func(pc.ProxyFunc()), nil
+}
@@ -1,28 +1,78 @@
 package restypool
 
 import (
+	"context"
 	"crypto/tls"
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/proxyconf"
 	"net"
 	"net/http"
-	"httpclientpool/pkg/config"
+	"net/url"
 
 	resty "resty.dev/v3"
 )
 
-func newHTTPTransport(cfg config.Config) *http.Transport {
-	return &http.Transport{
-		DialContext:           (&net.Dialer{Timeout: cfg.DialTimeout}).DialContext,
-		TLSClientConfig:       &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+// dialContext дозванивается до addr, если он задан (DNS-режим), иначе — до адреса из запроса.
+// SNI и Host при этом остаются от URL. Подменяется только адрес самого хоста из BaseURL:
+// соединение с прокси идёт на адрес прокси, а хост за ним резолвит прокси.
+// Для unix:// все соединения идут в сокет.
+func dialContext(cfg config.Config, addr string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	d := cfg.Dial()
+	if socket, ok := config.UnixSocket(cfg.BaseURL); ok {
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, "unix", socket)
+		}
+	}
+	if addr == "" {
+		return d.DialContext
+	}
+	target := config.HostPort(cfg.BaseURL)
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if address == target {
+			address = addr
+		}
+		return d.DialContext(ctx, network, address)
+	}
+}
+
+func newHTTPTransport(cfg config.Config, tc *tls.Config, pf proxyconf.Func, addr string) *http.Transport {
+	t := &http.Transport{
+		DialContext: dialContext(cfg, addr),
+		// Клон на каждый транспорт: net/http дописывает ALPN в NextProtos при включённом HTTP/2.
+		TLSClientConfig:       tc.Clone(),
 		TLSHandshakeTimeout:   cfg.TlsTimeout,
 		IdleConnTimeout:       cfg.IdleConnTimeout,
 		MaxConnsPerHost:       cfg.MaxConnsPerHost,
 		MaxIdleConnsPerHost:   cfg.MaxConnsPerHost,
 		MaxIdleConns:          cfg.Size * 2,
 		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
-		TLSNextProto:          map[string]func(string, *tls.Conn) http.RoundTripper{},
 	}
+
+	var protos http.Protocols
+	switch cfg.Protocol {
+	case config.ProtocolHTTP2:
+		protos.SetHTTP1(true)
+		protos.SetHTTP2(true)
+	case config.ProtocolH2C:
+		protos.SetUnencryptedHTTP2(true)
+	default:
+		protos.SetHTTP1(true)
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	t.Protocols = &protos
+
+	if pf != nil {
+		t.Proxy = func(r *http.Request) (*url.URL, error) { return pf(r.URL) }
+	}
+	return t
 }
 
-func newRestyClient(cfg config.Config) *resty.Client {
-	return resty.New().SetTimeout(cfg.RequestTimeout).SetTransport(newHTTPTransport(cfg)).SetBaseURL(cfg.BaseURL)
+func newRestyClient(cfg config.Config, tc *tls.Config, pf proxyconf.Func, addr string) *resty.Client {
+	base := cfg.BaseURL
+	if _, ok := config.UnixSocket(base); ok {
+		base = config.UnixHTTPBase
+	}
+	c := resty.New().SetTimeout(cfg.RequestTimeout).SetTransport(withBodyLimits(newHTTPTransport(cfg, tc, pf, addr), cfg)).SetBaseURL(base).
+		SetRequestMiddlewares(resty.PrepareRequestMiddleware, setContentLength, compressBody(cfg.Compression))
+	return withCompression(c, cfg.Compression)
 }
@@ -0,0 +1,64 @@
+package restypool
+
+import (
+	"io"
+	"net/http"
+
+	"httpclientpool/pkg/compress"
+	"httpclientpool/pkg/config"
+
+	resty "resty.dev/v3"
+)
+
+// withCompression заменяет встроенные распаковщики resty (они не ограничивают размер)
+// на распаковщики compress с пределом и оставляет в Accept-Encoding только cm.Accept.
+func withCompression(c *resty.Client, cm config.Compression) *resty.Client {
+	if len(cm.Accept) == 0 {
+		return c
+	}
+	limit := cm.DecompressLimit()
+	for _, enc := range []string{config.EncodingGzip, config.EncodingBrotli, config.EncodingZstd} {
+		c.AddContentDecompresser(enc, decompresser(enc, limit))
+	}
+	return c.SetContentDecompresserKeys(cm.Accept)
+}
+
+func decompresser(enc string, limit int64) resty.ContentDecompresser {
+	return func(body io.ReadCloser) (io.ReadCloser, error) {
+		r, err := compress.NewReader(enc, body, limit)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{ReadCloser: r, body: body}, nil
+	}
+}
+
+// decodedBody закрывает и декодер, и исходное тело ответа.
+type decodedBody struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.body.Close()
+}
+
+// compressBody — middleware после setContentLength: сжимает тело запроса потоком.
+// Длина сжатого тела заранее неизвестна, поэтому оно уходит chunked и без GetBody.
+// Тело, у которого вызывающий уже указал Content-Encoding, не трогается.
+func compressBody(cm config.Compression) resty.RequestMiddleware {
+	return func(_ *resty.Client, r *resty.Request) error {
+		raw := r.RawRequest
+		if raw == nil || raw.Body == nil || raw.Body == http.NoBody ||
+			raw.Header.Get("Content-Encoding") != "" || !compress.CompressRequest(cm, raw.ContentLength) {
+			return nil
+		}
+		raw.Body = compress.Pipe(cm.Request, raw.Body)
+		raw.ContentLength = -1
+		raw.GetBody = nil
+		raw.Header.Set("Content-Encoding", cm.Request)
+		raw.Header.Del("Content-Length")
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package restypool
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
+)
+
+// streamKey помечает ctx запросов Stream: на них bodyLimits не действует.
+type streamKey struct{}
+
+func streamContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamKey{}, true)
+}
+
+// bodyLimits — транспорт члена пула с MaxResponseBodySize и BodyReadTimeout. Тело считается
+// до распаковки: resty распаковывает уже поверх него.
+type bodyLimits struct {
+	*http.Transport
+	max     int64
+	timeout time.Duration
+}
+
+func withBodyLimits(t *http.Transport, cfg config.Config) http.RoundTripper {
+	if cfg.MaxResponseBodySize <= 0 && cfg.BodyReadTimeout <= 0 {
+		return t
+	}
+	return &bodyLimits{Transport: t, max: cfg.MaxResponseBodySize, timeout: cfg.BodyReadTimeout}
+}
+
+func (t *bodyLimits) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(streamKey{}) != nil {
+		return t.Transport.RoundTrip(req)
+	}
+	cancel := context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(req.Context())
+		req = req.WithContext(ctx)
+	}
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if t.max > 0 && resp.ContentLength > t.max {
+		_ = resp.Body.Close()
+		cancel()
+		return nil, &pool.BodyTooLargeError{Limit: t.max}
+	}
+	b := &limitedBody{rc: resp.Body, left: t.max, max: t.max, cancel: cancel}
+	if t.timeout > 0 {
+		// Отмена ctx запроса прерывает чтение тела; Read подменит ошибку на ErrBodyReadTimeout.
+		b.timer = time.AfterFunc(t.timeout, func() {
+			b.expired.Store(true)
+			cancel()
+		})
+	}
+	resp.Body = b
+	return resp, nil
+}
+
+type limitedBody struct {
+	rc      io.ReadCloser
+	left    int64
+	max     int64
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.max > 0 {
+		if b.left < 0 {
+			return 0, &pool.BodyTooLargeError{Limit: b.max}
+		}
+		// На байт больше остатка: тело ровно в max — не ошибка.
+		if int64(len(p)) > b.left+1 {
+			p = p[:b.left+1]
+		}
+	}
+	n, err := b.rc.Read(p)
+	if b.max > 0 {
+		if int64(n) > b.left {
+			n, b.left = int(b.left), -1
+			return n, &pool.BodyTooLargeError{Limit: b.max}
+		}
+		b.left -= int64(n)
+	}
+	if err != nil && err != io.EOF && b.expired.Load() {
+		err = pool.ErrBodyReadTimeout
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.rc.Close()
+	b.cancel()
+	return err
+}
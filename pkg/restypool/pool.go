@@ -2,9 +2,14 @@ package restypool
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/members"
 	"httpclientpool/pkg/pool"
-	"httpclientpool/pkg/rr"
+	"httpclientpool/pkg/proxyconf"
+	"httpclientpool/pkg/tlsconf"
+	"net/http"
 
 	"sync"
 
@@ -12,48 +17,190 @@ import (
 )
 
 var _ pool.Client = (*ClientPool)(nil)
+var _ pool.Inspector = (*ClientPool)(nil)
+var _ pool.Resizer = (*ClientPool)(nil)
+var _ pool.Warmer = (*ClientPool)(nil)
+var _ pool.Shutdowner = (*ClientPool)(nil)
+
+type conn struct {
+	client *resty.Client
+}
+
+func newConn(cfg config.Config, tc *tls.Config, pf proxyconf.Func, addr string) *conn {
+	return &conn{client: newRestyClient(cfg, tc, pf, addr)}
+}
 
 type ClientPool struct {
-	clients   []*resty.Client
-	spin      rr.RR
+	set       *members.Set[*conn]
 	cfg       config.Config
 	closeOnce sync.Once
 }
 
-func New(cfg config.Config) *ClientPool {
+func New(cfg config.Config) (*ClientPool, error) {
 	if cfg.Size <= 0 {
 		cfg.Size = config.DefaultConfig().Size
 	}
+	if cfg.InsecureSkipVerify {
+		cfg.Log().Warn("httpclientpool: TLS certificate verification is disabled", "pool", cfg.Name, "base_url", cfg.BaseURL)
+	}
+
+	tc, err := tlsconf.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pf, err := proxyconf.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := members.New(cfg, func(ep *members.Endpoint) *conn {
+		c := cfg
+		c.BaseURL = ep.URL
+		return newConn(c, tc, pf, ep.Addr)
+	}, func(c *conn) {
+		_ = c.client.Close()
+	})
+	if err != nil {
+		return nil, err
+	}
+	p := &ClientPool{set: set, cfg: cfg}
+	if err := set.WarmupOnNew(p.warm); err != nil {
+		set.Close()
+		return nil, err
+	}
+	if cfg.Name != "" {
+		pool.Register(cfg.Name, p)
+	}
+	return p, nil
+}
 
-	cs := make([]*resty.Client, 0, cfg.Size)
-	for i := 0; i < cfg.Size; i++ {
-		cs = append(cs, newRestyClient(cfg))
+// Warmup заранее устанавливает соединение каждого члена пула (см. config.Warmup)
+// и сообщает, какие члены прогреть не удалось.
+func (p *ClientPool) Warmup(ctx context.Context) (pool.WarmupReport, error) {
+	rep := p.set.Warmup(ctx, 0, p.warm)
+	return rep, rep.Err()
+}
+
+func (p *ClientPool) warm(ctx context.Context, c *conn) error {
+	r := c.client.R().SetContext(ctx)
+	if p.cfg.Warmup.Path == "" {
+		_, err := r.Head("")
+		return err
+	}
+	resp, err := r.Get(p.cfg.Warmup.Path)
+	if err != nil {
+		return err
 	}
-	return &ClientPool{clients: cs, cfg: cfg}
+	if resp.StatusCode() >= 400 {
+		return fmt.Errorf("warmup %s: status %d", p.cfg.Warmup.Path, resp.StatusCode())
+	}
+	return nil
 }
 
 func (p *ClientPool) Get(ctx context.Context, path string) (pool.Response, error) {
-	i := p.spin.Next(len(p.clients))
-	rr, err := p.clients[i].R().SetContext(ctx).Get(path)
+	return p.do(ctx, pool.Request{Path: path}, func(r *resty.Request) (*resty.Response, error) {
+		return r.Get(path)
+	})
+}
+
+func (p *ClientPool) Post(ctx context.Context, path string, body any) (pool.Response, error) {
+	req := pool.WithIdempotency(ctx, p.cfg.Idempotency, pool.Request{Method: http.MethodPost, Path: path})
+	return p.do(ctx, req, func(r *resty.Request) (*resty.Response, error) {
+		return r.SetHeaderMultiValues(req.Header).SetBody(body).Post(path)
+	})
+}
+
+// Do выполняет произвольный запрос; Body отправляется потоком, ответ читается целиком.
+func (p *ClientPool) Do(ctx context.Context, req pool.Request) (pool.Response, error) {
+	req = pool.WithIdempotency(ctx, p.cfg.Idempotency, req)
+	return p.do(ctx, req, func(r *resty.Request) (*resty.Response, error) {
+		return withRequest(r, req).Execute(method(req), req.Path)
+	})
+}
+
+func (p *ClientPool) do(ctx context.Context, req pool.Request, send func(*resty.Request) (*resty.Response, error)) (pool.Response, error) {
+	m, err := p.set.Acquire(ctx)
 	if err != nil {
+		req.CloseBody()
 		return nil, err
 	}
-	return newRestyResp(rr), nil
+	r := m.Client.client.R().SetContext(ctx)
+	if p.cfg.PooledBuffers {
+		r.SetDoNotParseResponse(true)
+	}
+	rr, err := send(r)
+	var resp pool.Response
+	if err == nil {
+		resp, err = p.response(rr)
+	} else if rr != nil && rr.Body != nil && p.cfg.PooledBuffers {
+		_ = rr.Body.Close()
+	}
+	if err = p.set.Release(ctx, m, req.Path, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-func (p *ClientPool) Post(ctx context.Context, path string, body any) (pool.Response, error) {
-	i := p.spin.Next(len(p.clients))
-	rr, err := p.clients[i].R().SetContext(ctx).SetBody(body).Post(path)
+// response читает тело: в режиме PooledBuffers — в буфер из пула (член пула ещё занят).
+func (p *ClientPool) response(rr *resty.Response) (pool.Response, error) {
+	if !p.cfg.PooledBuffers {
+		return newRestyResp(rr), nil
+	}
+	resp, err := newPooledResp(rr)
 	if err != nil {
 		return nil, err
 	}
-	return newRestyResp(rr), nil
+	return resp, nil
+}
+
+// Stream выполняет запрос без буферизации тела ответа: член пула занят, пока не закрыт Body.
+// RequestTimeout ограничивает весь обмен, включая чтение тела.
+func (p *ClientPool) Stream(ctx context.Context, req pool.Request) (*pool.StreamResponse, error) {
+	req = pool.WithIdempotency(ctx, p.cfg.Idempotency, req)
+	m, err := p.set.Acquire(ctx)
+	if err != nil {
+		req.CloseBody()
+		return nil, err
+	}
+	rr, err := withRequest(m.Client.client.R().SetContext(streamContext(ctx)).SetDoNotParseResponse(true), req).
+		Execute(method(req), req.Path)
+	if err != nil {
+		if rr != nil && rr.Body != nil {
+			_ = rr.Body.Close()
+		}
+		return nil, p.set.Release(ctx, m, req.Path, err)
+	}
+	return &pool.StreamResponse{
+		StatusCode: rr.StatusCode(),
+		Header:     rr.Header().Clone(),
+		Body:       p.set.Hold(ctx, m, req.Path, rr.Body),
+	}, nil
+}
+
+// Resize меняет число соединений пула на лету. При уменьшении лишние соединения
+// дорабатывают запросы в полёте и закрываются.
+func (p *ClientPool) Resize(n int) error {
+	return p.set.Resize(n)
+}
+
+func (p *ClientPool) Snapshot() pool.Snapshot {
+	return p.set.Snapshot("resty", p.cfg)
+}
+
+// Shutdown — мягкая остановка для SIGTERM: новые Get/Post получают pool.ErrClosed,
+// запросы в полёте дорабатывают (или до истечения ctx), затем соединения закрываются.
+func (p *ClientPool) Shutdown(ctx context.Context) error {
+	err := p.set.Shutdown(ctx)
+	p.Close()
+	return err
 }
 
+// Close закрывает пул сразу, не дожидаясь запросов в полёте.
 func (p *ClientPool) Close() {
 	p.closeOnce.Do(func() {
-		for _, c := range p.clients {
-			_ = c.Close()
+		if p.cfg.Name != "" {
+			pool.Unregister(p.cfg.Name, p)
 		}
+		p.set.Close()
 	})
 }
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/pool"
 )
 
 func newH1TLSServerWithHandler(h http.Handler) *httptest.Server {
@@ -46,7 +47,7 @@ func TestPool_Get_Post(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -81,7 +82,7 @@ func TestPool_ContextTimeout_Get(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
@@ -108,7 +109,7 @@ func TestPool_ContextCancel_Get(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -135,7 +136,7 @@ func TestPool_Parallel_NoRace(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -171,7 +172,7 @@ func TestPool_Close_Idempotent(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
-	p := New(cfg)
+	p := mustNew(t, cfg)
 
 	p.Close()
 	p.Close()
@@ -191,7 +192,7 @@ func TestPool_BaseURL_Join(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -218,7 +219,7 @@ func TestPool_ResponseHeaderTimeout(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 	cfg.ResponseHeaderTimeout = 50 * time.Millisecond
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -228,8 +229,7 @@ func TestPool_ResponseHeaderTimeout(t *testing.T) {
 	if err == nil {
 		t.Fatalf("expected response header timeout, got nil")
 	}
-	if !strings.Contains(err.Error(), "timeout awaiting response headers") &&
-		!strings.Contains(err.Error(), "deadline exceeded") {
+	if !errors.Is(err, pool.HeaderTimeout) {
 		t.Fatalf("want header timeout, got: %v", err)
 	}
 }
@@ -246,7 +246,7 @@ func TestPool_DefaultSize(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -275,7 +275,7 @@ func TestPool_DistributesAcrossConnections(t *testing.T) {
 	cfg.BaseURL = srv.URL
 	cfg.InsecureSkipVerify = true
 	cfg.Size = 8
-	p := New(cfg)
+	p := mustNew(t, cfg)
 	defer p.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -300,3 +300,99 @@ func TestPool_DistributesAcrossConnections(t *testing.T) {
 	}
 	t.Logf("unique TCP connections: %d", n)
 }
+
+func mustNew(t *testing.T, cfg config.Config) *ClientPool {
+	t.Helper()
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func TestPool_H2C(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	srv := httptest.NewUnstartedServer(h)
+	var protos http.Protocols
+	protos.SetHTTP1(true)
+	protos.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protos
+	srv.Start()
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Protocol = config.ProtocolH2C
+	p := mustNew(t, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp, err := p.Get(ctx, "/proto")
+	if err != nil {
+		t.Fatalf("h2c GET: %v", err)
+	}
+	if string(resp.Body()) != "HTTP/2.0" {
+		t.Fatalf("want HTTP/2.0, got %s", resp.Body())
+	}
+}
+
+func TestPool_H2_MaxConcurrentStreams(t *testing.T) {
+	var active, peak atomic.Int64
+	var mu sync.Mutex
+	seen := make(map[string]struct{})
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		mu.Lock()
+		seen[r.RemoteAddr] = struct{}{}
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	srv := httptest.NewUnstartedServer(h)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg := config.TestConfig()
+	cfg.BaseURL = srv.URL
+	cfg.Size = 1
+	cfg.Protocol = config.ProtocolHTTP2
+	cfg.MaxConcurrentStreams = 2
+	p := mustNew(t, cfg)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := p.Get(ctx, "/")
+			if err != nil || string(resp.Body()) != "HTTP/2.0" {
+				t.Errorf("GET: err=%v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Fatalf("want peak of 2 concurrent streams, got %d", got)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("want a single h2 connection, got %d", len(seen))
+	}
+}
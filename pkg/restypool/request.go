@@ -0,0 +1,52 @@
+package restypool
+
+import (
+	"io"
+	"net/http"
+
+	"httpclientpool/pkg/pool"
+
+	resty "resty.dev/v3"
+)
+
+func method(req pool.Request) string {
+	if req.Method == "" {
+		return http.MethodGet
+	}
+	return req.Method
+}
+
+// withRequest переносит в resty-запрос заголовки и тело pool.Request.
+func withRequest(r *resty.Request, req pool.Request) *resty.Request {
+	r.SetHeaderMultiValues(req.Header)
+	if req.Body != nil {
+		if req.Header.Get("Content-Type") == "" {
+			r.SetHeader("Content-Type", pool.DefaultBodyContentType)
+		}
+		r.SetBody(&body{Reader: req.Body, n: req.ContentLength})
+	}
+	return r
+}
+
+// body — тело запроса как io.ReadCloser, чтобы net/http закрыл исходный Body (в том
+// числе при ошибке), и с длиной, которую выставляет setContentLength.
+type body struct {
+	io.Reader
+	n int64
+}
+
+func (b *body) Close() error {
+	if c, ok := b.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// setContentLength — middleware после resty.PrepareRequestMiddleware: resty не знает длину
+// произвольного io.Reader, а net/http берёт её только из http.Request.ContentLength.
+func setContentLength(_ *resty.Client, r *resty.Request) error {
+	if b, ok := r.Body.(*body); ok && b.n > 0 && r.RawRequest != nil {
+		r.RawRequest.ContentLength = b.n
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package restypool
 
 import (
+	"net/http"
+
+	"github.com/valyala/bytebufferpool"
 	"resty.dev/v3"
 )
 
 type restyResp struct {
 	status int
 	body   []byte
+	header http.Header
 }
 
 func newRestyResp(r *resty.Response) restyResp {
@@ -14,8 +18,46 @@ func newRestyResp(r *resty.Response) restyResp {
 	return restyResp{
 		status: r.StatusCode(),
 		body:   b,
+		header: r.Header().Clone(),
+	}
+}
+
+func (r restyResp) StatusCode() int     { return r.status }
+func (r restyResp) Body() []byte        { return r.body }
+func (r restyResp) Header() http.Header { return r.header }
+func (r restyResp) Release()            {}
+
+// pooledResp — ответ в режиме PooledBuffers: тело читается из соединения прямо в буфер
+// из bytebufferpool (resty не разбирает ответ, SetDoNotParseResponse).
+type pooledResp struct {
+	status int
+	buf    *bytebufferpool.ByteBuffer
+	header http.Header
+}
+
+func newPooledResp(r *resty.Response) (*pooledResp, error) {
+	defer r.Body.Close()
+	buf := bytebufferpool.Get()
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		bytebufferpool.Put(buf)
+		return nil, err
 	}
+	return &pooledResp{status: r.StatusCode(), buf: buf, header: r.Header()}, nil
 }
 
-func (r restyResp) StatusCode() int { return r.status }
-func (r restyResp) Body() []byte    { return r.body }
+func (r *pooledResp) StatusCode() int     { return r.status }
+func (r *pooledResp) Header() http.Header { return r.header }
+
+func (r *pooledResp) Body() []byte {
+	if r.buf == nil {
+		return nil
+	}
+	return r.buf.B
+}
+
+func (r *pooledResp) Release() {
+	if r.buf != nil {
+		bytebufferpool.Put(r.buf)
+		r.buf = nil
+	}
+}
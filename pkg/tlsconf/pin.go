@@ -0,0 +1,79 @@
+package tlsconf
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// PinError — ни один сертификат, предъявленный сервером, не совпал с cfg.TLS.Pins.
+type PinError struct {
+	// Got — SPKI-пины сертификатов, которые предъявил сервер (в формате "sha256/...").
+	Got []string
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("tls: server public key does not match any configured pin (got %s)", strings.Join(e.Got, ", "))
+}
+
+// SPKIPin считает пин сертификата в формате "sha256/<base64>".
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func parsePins(pins []string) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(pins))
+	for _, p := range pins {
+		b64 := strings.TrimPrefix(strings.TrimSpace(p), "sha256/")
+		raw, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("tls: invalid SPKI pin %q", p)
+		}
+		set["sha256/"+b64] = struct{}{}
+	}
+	return set, nil
+}
+
+type pinVerifier struct {
+	pins       map[string]struct{}
+	reportOnly bool
+	log        *slog.Logger
+}
+
+func (v *pinVerifier) VerifyPeerCertificate(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	var certs []*x509.Certificate
+	if len(verifiedChains) > 0 {
+		for _, chain := range verifiedChains {
+			certs = append(certs, chain...)
+		}
+	} else {
+		// InsecureSkipVerify: цепочка не строилась, проверяем то, что прислал сервер.
+		for _, raw := range rawCerts {
+			c, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, c)
+		}
+	}
+
+	got := make([]string, 0, len(certs))
+	for _, c := range certs {
+		pin := SPKIPin(c)
+		if _, ok := v.pins[pin]; ok {
+			return nil
+		}
+		got = append(got, pin)
+	}
+
+	err := &PinError{Got: got}
+	if v.reportOnly {
+		v.log.Warn("httpclientpool: SPKI pin mismatch (report-only)", "err", err)
+		return nil
+	}
+	return err
+}
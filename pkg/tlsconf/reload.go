@@ -0,0 +1,79 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader отдаёт клиентский сертификат и перечитывает пару cert/key,
+// если при очередном рукопожатии у файлов изменилось время модификации или размер.
+type certReloader struct {
+	certFile, keyFile string
+	log               *slog.Logger
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod fileStamp
+	keyMod  fileStamp
+}
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+func stamp(path string) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{mod: fi.ModTime(), size: fi.Size()}, nil
+}
+
+func newCertReloader(certFile, keyFile string, log *slog.Logger) (*certReloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("tls: cert_file and key_file must be set together")
+	}
+	r := &certReloader{certFile: certFile, keyFile: keyFile, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) reload() error {
+	cs, err := stamp(r.certFile)
+	if err != nil {
+		return fmt.Errorf("tls: client cert: %w", err)
+	}
+	ks, err := stamp(r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls: client key: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls: load client key pair: %w", err)
+	}
+	r.cert, r.certMod, r.keyMod = &cert, cs, ks
+	return nil
+}
+
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cs, cerr := stamp(r.certFile)
+	ks, kerr := stamp(r.keyFile)
+	if cerr == nil && kerr == nil && (cs != r.certMod || ks != r.keyMod) {
+		if err := r.reload(); err != nil {
+			// Файлы могут быть записаны не одновременно — оставляем прежнюю пару до следующей попытки.
+			r.log.Warn("httpclientpool: client certificate reload failed, keeping previous", "err", err)
+		}
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,121 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"httpclientpool/pkg/config"
+)
+
+// New собирает *tls.Config из cfg.TLS и cfg.InsecureSkipVerify. Один и тот же
+// *tls.Config разделяется всеми членами пула.
+func New(cfg config.Config) (*tls.Config, error) {
+	t := cfg.TLS
+	tc := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		ServerName:         t.ServerName,
+	}
+
+	if t.RootCAFile != "" || t.RootCAPEM != "" {
+		roots, err := loadRoots(t)
+		if err != nil {
+			return nil, err
+		}
+		tc.RootCAs = roots
+	}
+
+	if t.MinVersion != "" {
+		v, err := ParseVersion(t.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		tc.MinVersion = v
+	}
+
+	if len(t.CipherSuites) > 0 {
+		ids, err := ParseCipherSuites(t.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tc.CipherSuites = ids
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		r, err := newCertReloader(t.CertFile, t.KeyFile, cfg.Log())
+		if err != nil {
+			return nil, err
+		}
+		tc.GetClientCertificate = r.GetClientCertificate
+	}
+
+	if len(t.Pins) > 0 {
+		pins, err := parsePins(t.Pins)
+		if err != nil {
+			return nil, err
+		}
+		v := &pinVerifier{pins: pins, reportOnly: t.PinReportOnly, log: cfg.Log()}
+		tc.VerifyPeerCertificate = v.VerifyPeerCertificate
+	}
+
+	return tc, nil
+}
+
+func loadRoots(t config.TLS) (*x509.CertPool, error) {
+	roots := x509.NewCertPool()
+	if t.RootCAFile != "" {
+		pem, err := os.ReadFile(t.RootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read root CA: %w", err)
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates found in %s", t.RootCAFile)
+		}
+	}
+	if t.RootCAPEM != "" && !roots.AppendCertsFromPEM([]byte(t.RootCAPEM)) {
+		return nil, errors.New("tls: no certificates found in root_ca_pem")
+	}
+	return roots, nil
+}
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseVersion принимает "1.2", "TLS1.2" или "TLS 1.2".
+func ParseVersion(s string) (uint16, error) {
+	k := strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TLS"))
+	if v, ok := versions[k]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("tls: unknown version %q", s)
+}
+
+// ParseCipherSuites переводит имена IANA (TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) в идентификаторы.
+// Небезопасные наборы из tls.InsecureCipherSuites не принимаются.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		known[cs.Name] = cs.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	var errs []error
+	for _, n := range names {
+		id, ok := known[strings.TrimSpace(n)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("tls: unknown or insecure cipher suite %q", n))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
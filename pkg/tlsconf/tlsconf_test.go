@@ -0,0 +1,51 @@
+package tlsconf_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"httpclientpool/pkg/config"
+	"httpclientpool/pkg/tlsconf"
+)
+
+func TestParseVersion(t *testing.T) {
+	for in, want := range map[string]uint16{"1.2": tls.VersionTLS12, "TLS1.3": tls.VersionTLS13, "tls 1.2": tls.VersionTLS12} {
+		got, err := tlsconf.ParseVersion(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseVersion(%q) = %x, %v; want %x", in, got, err, want)
+		}
+	}
+	if _, err := tlsconf.ParseVersion("1.4"); err == nil {
+		t.Fatalf("expected error for unknown version")
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := tlsconf.ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	if err != nil || len(ids) != 1 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Fatalf("got %v, %v", ids, err)
+	}
+	if _, err := tlsconf.ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Fatalf("expected insecure suite to be rejected")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TLS.RootCAPEM = "not a pem"
+	if _, err := tlsconf.New(cfg); err == nil {
+		t.Fatalf("expected error for bad root CA")
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.TLS.Pins = []string{"sha256/short"}
+	if _, err := tlsconf.New(cfg); err == nil {
+		t.Fatalf("expected error for invalid pin")
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.TLS.CertFile = "client.crt"
+	if _, err := tlsconf.New(cfg); err == nil {
+		t.Fatalf("expected error for cert without key")
+	}
+}